@@ -1,5 +1,13 @@
 package drawer
 
+import (
+	"QRCodeGenerator/logger"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+)
+
 const (
 	BLACK_COLOR uint8 = 1
 	WHITE_COLOR uint8 = 2
@@ -7,3 +15,98 @@ const (
 	BLUE_COLOR  uint8 = 4
 	RED_COLOR   uint8 = 0
 )
+
+// MIN_CONTRAST_RATIO is the lowest luminance contrast between dark and light
+// modules accepted by CheckContrast, measured like the WCAG contrast ratio
+const MIN_CONTRAST_RATIO = 3.0
+
+type Colors struct {
+	Dark      color.Color
+	Light     color.Color
+	QuietZone color.Color // nil uses the Light color
+	Strict    bool        // refuse low contrast or inverted colors instead of only warning
+
+	// Reversed is set when Dark is lighter than Light on purpose, for reflectance
+	// reversed symbols like light marks on a dark surface, see ReversedColors
+	Reversed bool
+}
+
+var DefaultColors = Colors{Dark: color.Black, Light: color.White}
+
+// ReversedColors swaps the dark and light colors to draw a reflectance reversed symbol,
+// the quiet zone takes the new light color unless it was set
+func ReversedColors(colors Colors) Colors {
+	colors.Dark, colors.Light = colors.Light, colors.Dark
+	colors.Reversed = true
+	return colors
+}
+
+// relativeLuminance returns the luminance of c composed over white, since a
+// transparent background will usually be printed or shown on something light
+func relativeLuminance(c color.Color) float64 {
+	r, g, b, a := c.RGBA()
+	white := float64(0xffff - a)
+	linear := func(channel uint32) float64 {
+		value := (float64(channel) + white) / 0xffff
+		if value <= 0.04045 {
+			return value / 12.92
+		}
+		return math.Pow((value+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
+
+func ContrastRatio(dark color.Color, light color.Color) float64 {
+	darkLuminance := relativeLuminance(dark)
+	lightLuminance := relativeLuminance(light)
+	if darkLuminance > lightLuminance {
+		darkLuminance, lightLuminance = lightLuminance, darkLuminance
+	}
+	return (lightLuminance + 0.05) / (darkLuminance + 0.05)
+}
+
+// getColors returns the colors to draw with, DefaultColors when colors is nil
+func getColors(colors *Colors) (Colors, error) {
+	if colors == nil {
+		return DefaultColors, nil
+	}
+	if colors.Dark == nil || colors.Light == nil {
+		return Colors{}, errors.New("dark and light colors are needed")
+	}
+	if err := CheckContrast(*colors); err != nil {
+		return Colors{}, err
+	}
+	return *colors, nil
+}
+
+// CheckContrast returns an error when the colors would be hard to scan, dark modules
+// lighter than the light ones are also reported since many scanners can not read them,
+// unless colors.Reversed is set. Without colors.Strict the problems are only logged and
+// nil is returned.
+func CheckContrast(colors Colors) error {
+	var problem string
+	quietZone := colors.QuietZone
+	if quietZone == nil {
+		quietZone = colors.Light
+	}
+
+	if ratio := ContrastRatio(colors.Dark, colors.Light); ratio < MIN_CONTRAST_RATIO {
+		problem = fmt.Sprintf("contrast between dark and light modules is too low: %.2f:1, at least %.1f:1 is needed", ratio, MIN_CONTRAST_RATIO)
+	} else if inverted := relativeLuminance(colors.Dark) > relativeLuminance(colors.Light); inverted && !colors.Reversed {
+		problem = "dark modules are lighter than light modules, many scanners can not read inverted codes"
+	} else if (relativeLuminance(colors.Dark) > relativeLuminance(quietZone)) != inverted || ContrastRatio(colors.Dark, quietZone) < MIN_CONTRAST_RATIO {
+		problem = "quiet zone is too dark for the finder patterns to be found"
+		if inverted {
+			problem = "quiet zone is too light for the finder patterns of a reversed symbol to be found"
+		}
+	}
+
+	if problem == "" {
+		return nil
+	}
+	if colors.Strict {
+		return errors.New(problem)
+	}
+	logger.Warn(problem)
+	return nil
+}
@@ -0,0 +1,110 @@
+package drawer
+
+import "image"
+
+// directions used while walking the contours, the y axis points down like in the matrix
+const (
+	directionEast = iota
+	directionSouth
+	directionWest
+	directionNorth
+)
+
+var directionSteps = [4]image.Point{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+
+// traceContours returns the outlines of every region of adjacent dark modules.
+// Points are module corners (X is the column and Y the row), each contour is closed
+// and only keeps the corners where the direction changes. Outer borders go clockwise
+// and holes go counter clockwise, modules touching only by a corner are kept apart.
+func traceContours(QRArray [][]uint8) [][]image.Point {
+	size := len(QRArray)
+	dark := func(i int, j int) bool {
+		return i >= 0 && j >= 0 && i < size && j < size && isDark(QRArray[i][j])
+	}
+
+	// edges[y][x][direction] is true if there is an edge leaving the corner (x, y) in that direction,
+	// every edge has a dark module on its right
+	edges := make([][][4]bool, size+1)
+	for y := range edges {
+		edges[y] = make([][4]bool, size+1)
+	}
+	for i := range size {
+		for j := range size {
+			if !dark(i, j) {
+				continue
+			}
+			if !dark(i-1, j) {
+				edges[i][j][directionEast] = true
+			}
+			if !dark(i, j+1) {
+				edges[i][j+1][directionSouth] = true
+			}
+			if !dark(i+1, j) {
+				edges[i+1][j+1][directionWest] = true
+			}
+			if !dark(i, j-1) {
+				edges[i+1][j][directionNorth] = true
+			}
+		}
+	}
+
+	var contours [][]image.Point
+	for y := range edges {
+		for x := range edges[y] {
+			for direction := range 4 {
+				if !edges[y][x][direction] {
+					continue
+				}
+				contours = append(contours, walkContour(edges, image.Point{x, y}, direction))
+			}
+		}
+	}
+	return contours
+}
+
+// walkContour follows the edges from start until it gets back, removing them from edges
+func walkContour(edges [][][4]bool, start image.Point, direction int) []image.Point {
+	var contour []image.Point
+	point := start
+	lastDirection := -1
+	for {
+		edges[point.Y][point.X][direction] = false
+		if direction != lastDirection {
+			contour = append(contour, point)
+		}
+		lastDirection = direction
+		point = point.Add(directionSteps[direction])
+
+		// turning right first keeps regions that only touch by a corner separated
+		next := -1
+		for _, turn := range []int{1, 0, 3} {
+			candidate := (direction + turn) % 4
+			if edges[point.Y][point.X][candidate] {
+				next = candidate
+				break
+			}
+		}
+		if next == -1 {
+			break
+		}
+		direction = next
+	}
+
+	// the starting corner is not a real corner if the walk ended going the same way
+	if len(contour) > 1 && lastDirection == directionOf(contour[0], contour[1]) {
+		contour = contour[1:]
+	}
+	return contour
+}
+
+func directionOf(from image.Point, to image.Point) int {
+	switch {
+	case to.X > from.X:
+		return directionEast
+	case to.Y > from.Y:
+		return directionSouth
+	case to.X < from.X:
+		return directionWest
+	}
+	return directionNorth
+}
@@ -2,26 +2,36 @@ package drawer
 
 import (
 	"QRCodeGenerator/generator"
+	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
-	"image/png"
+	"io"
 	"os"
 )
 
-func saveImage(image *image.RGBA, saveLocation string) {
-	myfile, err := os.Create(saveLocation)
-	if err != nil {
-		panic(err)
+const (
+	defaultCellSize  = 10
+	defaultQuietZone = 5 // in modules
+)
+
+func cellColor(cell uint8) color.RGBA {
+	switch cell {
+	case BLACK_COLOR:
+		return color.RGBA{0, 0, 0, 255}
+	case WHITE_COLOR:
+		return color.RGBA{255, 255, 255, 255}
+	case GREEN_COLOR:
+		return color.RGBA{0, 255, 0, 255}
+	case BLUE_COLOR:
+		return color.RGBA{0, 0, 255, 255}
 	}
-	defer myfile.Close()
-	png.Encode(myfile, image)
+	return color.RGBA{255, 0, 0, 255} // to debug basically
 }
 
-func DrawQRCode(QRArray [][]uint8, QRversion generator.QRCodeInfo, locationToSave string) {
-	cellSize := 10
-	quietArea := 100
-	imageSize := (QRversion.Size * cellSize) + quietArea
+func drawQRImage(QRArray [][]uint8, cellSize int, quietZone int) *image.RGBA {
+	quietArea := quietZone * cellSize * 2
+	imageSize := (len(QRArray) * cellSize) + quietArea
 
 	backgroundColor := color.RGBA{255, 255, 255, 255} // white
 	QRImage := image.NewRGBA(image.Rect(0, 0, imageSize, imageSize))
@@ -29,24 +39,65 @@ func DrawQRCode(QRArray [][]uint8, QRversion generator.QRCodeInfo, locationToSav
 
 	for i := range QRArray {
 		for jPosition, j := range QRArray[i] {
-			var colorCell color.RGBA
-
-			switch j {
-			case BLACK_COLOR:
-				colorCell = color.RGBA{0, 0, 0, 255}
-			case WHITE_COLOR:
-				colorCell = color.RGBA{255, 255, 255, 255}
-			case GREEN_COLOR:
-				colorCell = color.RGBA{0, 255, 0, 255}
-			case BLUE_COLOR:
-				colorCell = color.RGBA{0, 0, 255, 255}
+			cell := image.Rect(quietArea/2+cellSize*jPosition, quietArea/2+cellSize*i, quietArea/2+cellSize*(jPosition+1), quietArea/2+cellSize*(i+1))
+			draw.Draw(QRImage, cell, &image.Uniform{cellColor(j)}, image.ZP, draw.Src)
+		}
+	}
+	return QRImage
+}
+
+func DrawQRCode(QRArray [][]uint8, QRversion generator.QRCodeInfo, locationToSave string) {
+	myfile, err := os.Create(locationToSave)
+	if err != nil {
+		panic(err)
+	}
+	defer myfile.Close()
+	if err := Render(myfile, QRArray, QRversion); err != nil {
+		panic(err)
+	}
+}
+
+// Render writes the same PNG as DrawQRCode to w, for HTTP responses or in memory buffers
+func Render(w io.Writer, QRArray [][]uint8, QRversion generator.QRCodeInfo) error {
+	return RenderPNG(w, QRArray, PNGOptions{Scale: defaultCellSize, QuietZone: defaultQuietZone, DPI: DEFAULT_DPI, Metadata: &QRversion})
+}
+
+// DrawOnto draws the code on dst with its top left corner at the point at, the code
+// plus quietZone modules on every side are scaled to fill a square of size pixels.
+// A nil colors uses DefaultColors, debug colors of unfinished matrices are kept.
+func DrawOnto(dst draw.Image, at image.Point, size int, QRArray [][]uint8, quietZone int, colors *Colors) error {
+	if err := checkSquareMatrix(QRArray); err != nil {
+		return err
+	}
+	modules := len(QRArray) + 2*max(quietZone, 0)
+	if err := checkBitmapParams(size/modules, quietZone); err != nil {
+		return fmt.Errorf("drawing %d modules in %d pixels: %w", modules, size, err)
+	}
+	drawColors, err := getColors(colors)
+	if err != nil {
+		return err
+	}
+	quietZoneColor := drawColors.QuietZone
+	if quietZoneColor == nil {
+		quietZoneColor = drawColors.Light
+	}
+
+	area := image.Rect(at.X, at.Y, at.X+size, at.Y+size).Intersect(dst.Bounds())
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		i := (y-at.Y)*modules/size - quietZone
+		for x := area.Min.X; x < area.Max.X; x++ {
+			j := (x-at.X)*modules/size - quietZone
+			switch {
+			case i < 0 || j < 0 || i >= len(QRArray) || j >= len(QRArray):
+				dst.Set(x, y, quietZoneColor)
+			case QRArray[i][j] == BLACK_COLOR:
+				dst.Set(x, y, drawColors.Dark)
+			case QRArray[i][j] == WHITE_COLOR:
+				dst.Set(x, y, drawColors.Light)
 			default:
-				colorCell = color.RGBA{255, 0, 0, 255} // to debug basically
+				dst.Set(x, y, cellColor(QRArray[i][j]))
 			}
-
-			cell := image.Rect(quietArea/2+cellSize*jPosition, quietArea/2+cellSize*i, quietArea/2+cellSize*(jPosition+1), quietArea/2+cellSize*(i+1))
-			draw.Draw(QRImage, cell, &image.Uniform{colorCell}, image.ZP, draw.Src)
 		}
 	}
-	saveImage(QRImage, locationToSave)
+	return nil
 }
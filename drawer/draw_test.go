@@ -0,0 +1,53 @@
+package drawer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDrawOnto(t *testing.T) {
+	const D, L = BLACK_COLOR, WHITE_COLOR
+	QRArray := [][]uint8{{D, L}, {L, D}}
+	red := color.RGBA{255, 0, 0, 255}
+	dst := image.NewRGBA(image.Rect(0, 0, 10, 10))
+
+	// 2 modules and 1 of quiet zone on every side fill 8 pixels, 2 per module
+	colors := &Colors{Dark: color.Black, Light: color.White, QuietZone: red}
+	if err := DrawOnto(dst, image.Pt(1, 1), 8, QRArray, 1, colors); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		x, y int
+		want color.Color
+	}{
+		{0, 0, color.RGBA{}}, // outside of the area
+		{1, 1, red},
+		{3, 3, color.Black},
+		{5, 3, color.White},
+		{6, 6, color.Black},
+		{7, 8, red},
+	} {
+		if got := color.RGBAModel.Convert(dst.At(test.x, test.y)); got != color.RGBAModel.Convert(test.want) {
+			t.Errorf("pixel (%d, %d) is %v, want %v", test.x, test.y, got, test.want)
+		}
+	}
+
+	for _, test := range []struct {
+		name      string
+		size      int
+		QRArray   [][]uint8
+		quietZone int
+	}{
+		{"no size", 0, QRArray, 1},
+		{"negative size", -8, QRArray, 1},
+		{"smaller than a pixel per module", 3, QRArray, 1},
+		{"negative quiet zone", 8, QRArray, -1},
+		{"empty matrix", 8, nil, 1},
+		{"not square", 8, [][]uint8{{D, L}, {L}}, 1},
+	} {
+		if err := DrawOnto(dst, image.Pt(0, 0), test.size, test.QRArray, test.quietZone, nil); err == nil {
+			t.Errorf("%s: did not fail", test.name)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package drawer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type DXFOptions struct {
+	ModuleSize float64 // in mm
+	Layer      string
+}
+
+// WriteDXF writes an R12 DXF with a closed polyline for every dark region, holes
+// are written as their own polylines. The origin is the lower left corner of the code.
+func WriteDXF(w io.Writer, QRArray [][]uint8, options DXFOptions) error {
+	if options.ModuleSize == 0 {
+		options.ModuleSize = 1
+	}
+	if options.ModuleSize < 0 {
+		return errors.New("module size can not be negative")
+	}
+	if options.Layer == "" {
+		options.Layer = "QRCODE"
+	}
+	size := len(QRArray)
+
+	buffer := bufio.NewWriter(w)
+	buffer.WriteString("0\nSECTION\n2\nENTITIES\n")
+	for _, contour := range traceContours(QRArray) {
+		fmt.Fprintf(buffer, "0\nPOLYLINE\n8\n%s\n66\n1\n70\n1\n", options.Layer)
+		for _, point := range contour {
+			// DXF has the y axis pointing up
+			x := float64(point.X) * options.ModuleSize
+			y := float64(size-point.Y) * options.ModuleSize
+			fmt.Fprintf(buffer, "0\nVERTEX\n8\n%s\n10\n%.4f\n20\n%.4f\n", options.Layer, x, y)
+		}
+		fmt.Fprintf(buffer, "0\nSEQEND\n8\n%s\n", options.Layer)
+	}
+	buffer.WriteString("0\nENDSEC\n0\nEOF\n")
+	return buffer.Flush()
+}
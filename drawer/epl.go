@@ -0,0 +1,35 @@
+package drawer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WriteEPL writes a full label for Eltron printers with the code as a GW command
+func WriteEPL(w io.Writer, QRArray [][]uint8, options LabelOptions) error {
+	if err := options.setDefaults(); err != nil {
+		return err
+	}
+	size := bitmapSize(QRArray, options.ModuleSize, options.QuietZone)
+	if err := options.checkFitsLabel(size); err != nil {
+		return err
+	}
+	bytesPerRow := (size + 7) / 8
+
+	buffer := bufio.NewWriter(w)
+	// EPL lines must start with a new line to be sure the printer is not in the middle of a command
+	buffer.WriteString("\nN\n")
+	fmt.Fprintf(buffer, "q%d\n", options.LabelWidth*options.DotsPerMM)
+	fmt.Fprintf(buffer, "Q%d,%d\n", options.LabelHeight*options.DotsPerMM, *options.Gap*options.DotsPerMM)
+	fmt.Fprintf(buffer, "D%d\n", *options.Density)
+
+	fmt.Fprintf(buffer, "GW%d,%d,%d,%d,", options.X, options.Y, bytesPerRow, size)
+	for y := range size {
+		buffer.Write(packRowInverted(QRArray, options.ModuleSize, options.QuietZone, y))
+	}
+	buffer.WriteString("\n")
+
+	fmt.Fprintf(buffer, "P%d\n", options.Copies)
+	return buffer.Flush()
+}
@@ -0,0 +1,112 @@
+package drawer
+
+import (
+	"QRCodeGenerator/generator"
+	"bufio"
+	"errors"
+	"io"
+)
+
+type ESCPOSOptions struct {
+	DotSize    int  // printer dots per module
+	PaperWidth int  // printable width in dots, 384 for 58mm paper and 576 for 80mm paper
+	QuietZone  int  // modules of margin around the raster image
+	Native     bool // let the printer encode the code with GS ( k instead of sending the matrix
+}
+
+const (
+	escposESC = 0x1B
+	escposGS  = 0x1D
+)
+
+// escposErrorLevel returns the value GS ( k expects for each error level
+func escposErrorLevel(errorLevel generator.ErrorLevel) byte {
+	switch errorLevel {
+	case generator.ErrorLevel_L:
+		return 48
+	case generator.ErrorLevel_M:
+		return 49
+	case generator.ErrorLevel_Q:
+		return 50
+	case generator.ErrorLevel_H:
+		return 51
+	}
+	return 49
+}
+
+// WriteESCPOS writes the commands to print the code centered on the paper, the
+// printer is not initialized so the output can be placed in the middle of a receipt
+func WriteESCPOS(w io.Writer, QRArray [][]uint8, QRversion generator.QRCodeInfo, options ESCPOSOptions) error {
+	if options.DotSize == 0 {
+		options.DotSize = 4
+	}
+	if options.PaperWidth == 0 {
+		options.PaperWidth = 384
+	}
+	if err := checkBitmapParams(options.DotSize, options.QuietZone); err != nil {
+		return err
+	}
+
+	buffer := bufio.NewWriter(w)
+	//center
+	buffer.Write([]byte{escposESC, 'a', 1})
+
+	if options.Native {
+		if err := writeESCPOSNative(buffer, QRversion, options); err != nil {
+			return err
+		}
+	} else {
+		if err := writeESCPOSRaster(buffer, QRArray, options); err != nil {
+			return err
+		}
+	}
+
+	//back to left alignment
+	buffer.Write([]byte{escposESC, 'a', 0})
+	return buffer.Flush()
+}
+
+func writeESCPOSNative(buffer *bufio.Writer, QRversion generator.QRCodeInfo, options ESCPOSOptions) error {
+	if QRversion.InfoToEncode == "" {
+		return errors.New("native ESC/POS codes need the data to encode")
+	}
+	if options.DotSize > 16 {
+		return errors.New("dot size must be between 1 and 16 for native codes")
+	}
+	if QRversion.Size*options.DotSize > options.PaperWidth {
+		return errors.New("QR code is wider than the paper")
+	}
+	dataLength := len(QRversion.InfoToEncode) + 3
+	if dataLength > 0xFFFF {
+		return errors.New("data too long")
+	}
+
+	//model 2
+	buffer.Write([]byte{escposGS, '(', 'k', 4, 0, 49, 65, 50, 0})
+	//module size
+	buffer.Write([]byte{escposGS, '(', 'k', 3, 0, 49, 67, byte(options.DotSize)})
+	//error correction level
+	buffer.Write([]byte{escposGS, '(', 'k', 3, 0, 49, 69, escposErrorLevel(QRversion.ErrorLevel)})
+	//store the data
+	buffer.Write([]byte{escposGS, '(', 'k', byte(dataLength % 256), byte(dataLength / 256), 49, 80, 48})
+	buffer.WriteString(QRversion.InfoToEncode)
+	//print
+	buffer.Write([]byte{escposGS, '(', 'k', 3, 0, 49, 81, 48})
+	return nil
+}
+
+func writeESCPOSRaster(buffer *bufio.Writer, QRArray [][]uint8, options ESCPOSOptions) error {
+	size := bitmapSize(QRArray, options.DotSize, options.QuietZone)
+	if size > options.PaperWidth {
+		return errors.New("QR code is wider than the paper")
+	}
+	bytesPerRow := (size + 7) / 8
+
+	// GS v 0, normal density
+	buffer.Write([]byte{escposGS, 'v', '0', 0})
+	buffer.Write([]byte{byte(bytesPerRow % 256), byte(bytesPerRow / 256), byte(size % 256), byte(size / 256)})
+	for y := range size {
+		buffer.Write(packRow(QRArray, options.DotSize, options.QuietZone, y, true))
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package drawer
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Fill gives the color of the dark module (i, j) of a code with size modules per side.
+// Every module gets a single color so its luminance can be checked on its own.
+type Fill interface {
+	ModuleColor(i int, j int, size int) color.Color
+}
+
+// moduleCenter returns the center of the module as a fraction of the code side
+func moduleCenter(i int, j int, size int) (float64, float64) {
+	return (float64(j) + 0.5) / float64(size), (float64(i) + 0.5) / float64(size)
+}
+
+// mixColors interpolates from a to b, t goes from 0 to 1
+func mixColors(a color.Color, b color.Color, t float64) color.Color {
+	t = math.Min(math.Max(t, 0), 1)
+	from := color.NRGBAModel.Convert(a).(color.NRGBA)
+	to := color.NRGBAModel.Convert(b).(color.NRGBA)
+	mix := func(from uint8, to uint8) uint8 {
+		return uint8(float64(from)*(1-t) + float64(to)*t + 0.5)
+	}
+	return color.NRGBA{mix(from.R, to.R), mix(from.G, to.G), mix(from.B, to.B), mix(from.A, to.A)}
+}
+
+type LinearGradient struct {
+	From  color.Color
+	To    color.Color
+	Angle float64 // direction in degrees, 0 goes from left to right and 90 from top to bottom
+}
+
+func (gradient LinearGradient) ModuleColor(i int, j int, size int) color.Color {
+	x, y := moduleCenter(i, j, size)
+	angle := gradient.Angle * math.Pi / 180
+	dx, dy := math.Cos(angle), math.Sin(angle)
+	// project on the direction, scaled so the corners of the code reach 0 and 1
+	extent := (math.Abs(dx) + math.Abs(dy)) / 2
+	t := ((x-0.5)*dx+(y-0.5)*dy)/(2*extent) + 0.5
+	return mixColors(gradient.From, gradient.To, t)
+}
+
+// RadialGradient goes from Inner in the center of the code to Outer in the corners
+type RadialGradient struct {
+	Inner color.Color
+	Outer color.Color
+}
+
+func (gradient RadialGradient) ModuleColor(i int, j int, size int) color.Color {
+	x, y := moduleCenter(i, j, size)
+	return mixColors(gradient.Inner, gradient.Outer, math.Hypot(x-0.5, y-0.5)/math.Sqrt(0.5))
+}
+
+// ImageFill stretches the image over the code, every module gets the average color of
+// the part of the image it covers
+type ImageFill struct {
+	Image image.Image
+}
+
+func (fill ImageFill) ModuleColor(i int, j int, size int) color.Color {
+	bounds := fill.Image.Bounds()
+	x0 := bounds.Min.X + j*bounds.Dx()/size
+	y0 := bounds.Min.Y + i*bounds.Dy()/size
+	x1 := max(bounds.Min.X+(j+1)*bounds.Dx()/size, x0+1)
+	y1 := max(bounds.Min.Y+(i+1)*bounds.Dy()/size, y0+1)
+
+	var r, g, b, a, count uint64
+	for y := y0; y < min(y1, bounds.Max.Y); y++ {
+		for x := x0; x < min(x1, bounds.Max.X); x++ {
+			cr, cg, cb, ca := fill.Image.At(x, y).RGBA()
+			r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+			count++
+		}
+	}
+	if count == 0 {
+		return color.Transparent
+	}
+	return color.RGBA64{uint16(r / count), uint16(g / count), uint16(b / count), uint16(a / count)}
+}
+
+// darkEnough darkens c, keeping its hue, until its contrast against light reaches minContrast.
+// Translucent colors are made opaque first, since over a light background they may
+// never get dark enough.
+func darkEnough(c color.Color, light color.Color, minContrast float64) color.Color {
+	isDarkEnough := func(c color.Color) bool {
+		return relativeLuminance(c) <= relativeLuminance(light) && ContrastRatio(c, light) >= minContrast
+	}
+	if isDarkEnough(c) {
+		return c
+	}
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	nrgba.A = 0xff
+	scaled := func(factor float64) color.NRGBA {
+		return color.NRGBA{uint8(float64(nrgba.R) * factor), uint8(float64(nrgba.G) * factor), uint8(float64(nrgba.B) * factor), nrgba.A}
+	}
+	if isDarkEnough(nrgba) {
+		return nrgba
+	}
+
+	low, high := 0.0, 1.0
+	for range 16 {
+		middle := (low + high) / 2
+		if isDarkEnough(scaled(middle)) {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	return scaled(low)
+}
+
+// moduleColor returns the color of the dark module (i, j), finder patterns are not
+// filled since scanners look for them first
+func (style Style) moduleColor(i int, j int, size int, colors Colors) color.Color {
+	if style.Fill == nil {
+		return colors.Dark
+	}
+	minContrast := style.FillContrast
+	if minContrast <= 0 {
+		minContrast = MIN_CONTRAST_RATIO
+	}
+	return darkEnough(style.Fill.ModuleColor(i, j, size), colors.Light, minContrast)
+}
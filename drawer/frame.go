@@ -0,0 +1,239 @@
+package drawer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"strings"
+)
+
+// FRAME_MIN_QUIET_ZONE is the quiet zone a framed code needs, in modules, so the border
+// and the text are not read as part of the finder patterns
+const FRAME_MIN_QUIET_ZONE = 4
+
+// FrameOptions puts the code inside a frame with lines of text above and below it,
+// sizes are in modules so the same options work for the PNG and the SVG
+type FrameOptions struct {
+	Above []string
+	Below []string
+
+	TextSize     float64 // size of a font pixel, 0 uses 0.5
+	Border       float64 // thickness of the border, 0 draws no border
+	CornerRadius float64 // radius of the outer corners of the frame
+	Padding      float64 // space between the border and the content
+
+	BorderColor color.Color // nil uses the dark color
+	TextColor   color.Color // nil uses the dark color
+	Background  color.Color // nil uses the light color
+}
+
+// frameLayout has the position of every part of the frame, in the units it was computed with
+type frameLayout struct {
+	Width, Height float64
+	CodeX, CodeY  float64
+	AboveY        float64
+	BelowY        float64
+	FontPixel     float64
+}
+
+// getFrameLayout places the frame around a code of codeSide, unit is the size of a module
+func getFrameLayout(options FrameOptions, codeSide float64, unit float64, fontPixel float64) frameLayout {
+	inset := (options.Border + options.Padding) * unit
+	lineHeight := float64(FONT_LEADING) * fontPixel
+	textWidth := float64(max(maxTextWidth(options.Above), maxTextWidth(options.Below))) * fontPixel
+	contentWidth := math.Max(codeSide, textWidth)
+
+	layout := frameLayout{FontPixel: fontPixel}
+	layout.Width = contentWidth + 2*inset
+	layout.AboveY = inset
+	layout.CodeX = (layout.Width - codeSide) / 2
+	layout.CodeY = layout.AboveY + float64(len(options.Above))*lineHeight
+	layout.BelowY = layout.CodeY + codeSide
+	layout.Height = layout.BelowY + float64(len(options.Below))*lineHeight + inset
+	return layout
+}
+
+// lineX returns where the line starts so it is centered on the frame
+func (layout frameLayout) lineX(line string) float64 {
+	return (layout.Width - float64(textWidth(line))*layout.FontPixel) / 2
+}
+
+func (options FrameOptions) withDefaults(colors Colors) FrameOptions {
+	if options.TextSize <= 0 {
+		options.TextSize = 0.5
+	}
+	if options.BorderColor == nil {
+		options.BorderColor = colors.Dark
+	}
+	if options.TextColor == nil {
+		options.TextColor = colors.Dark
+	}
+	if options.Background == nil {
+		options.Background = colors.Light
+	}
+	return options
+}
+
+func checkFrameOptions(options FrameOptions, quietZone int) error {
+	if options.Border < 0 || options.Padding < 0 || options.CornerRadius < 0 || options.TextSize < 0 {
+		return errors.New("frame sizes can not be negative")
+	}
+	if quietZone < FRAME_MIN_QUIET_ZONE {
+		return fmt.Errorf("a framed code needs a quiet zone of at least %d modules, not %d", FRAME_MIN_QUIET_ZONE, quietZone)
+	}
+	return nil
+}
+
+// roundedBoxDistance is the signed distance to a rectangle of half sides halfWidth and
+// halfHeight with all the corners rounded by radius
+func roundedBoxDistance(center [2]float64, halfWidth float64, halfHeight float64, radius float64, p [2]float64) float64 {
+	radius = math.Min(math.Max(radius, 0), math.Min(halfWidth, halfHeight))
+	qx := math.Abs(p[0]-center[0]) - (halfWidth - radius)
+	qy := math.Abs(p[1]-center[1]) - (halfHeight - radius)
+	outside := math.Hypot(math.Max(qx, 0), math.Max(qy, 0))
+	inside := math.Min(math.Max(qx, qy), 0)
+	return outside + inside - radius
+}
+
+// drawFrameBox draws the background of the frame and its border on img
+func drawFrameBox(img *image.RGBA, options FrameOptions, border float64, radius float64) {
+	bounds := img.Bounds()
+	halfWidth := float64(bounds.Dx()) / 2
+	halfHeight := float64(bounds.Dy()) / 2
+	center := [2]float64{halfWidth, halfHeight}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := [2]float64{float64(x) + 0.5, float64(y) + 0.5}
+			distance := roundedBoxDistance(center, halfWidth, halfHeight, radius, p)
+			blendPixel(img, x, y, options.Background, 0.5-distance)
+			if border > 0 {
+				innerDistance := roundedBoxDistance(center, halfWidth-border, halfHeight-border, math.Max(radius-border, 0), p)
+				coverage := math.Min(0.5-distance, 1) * math.Min(math.Max(0.5+innerDistance, 0), 1)
+				blendPixel(img, x, y, options.BorderColor, coverage)
+			}
+		}
+	}
+}
+
+// DrawFramed draws the code like RenderPNG and puts it inside the frame, the quiet zone
+// must have at least FRAME_MIN_QUIET_ZONE modules
+func DrawFramed(QRArray [][]uint8, pngOptions PNGOptions, options FrameOptions) (*image.RGBA, error) {
+	if err := checkFrameOptions(options, pngOptions.QuietZone); err != nil {
+		return nil, err
+	}
+	code, err := drawPNGImage(QRArray, &pngOptions)
+	if err != nil {
+		return nil, err
+	}
+	colors := DefaultColors
+	if pngOptions.Colors != nil {
+		colors = *pngOptions.Colors
+	}
+	options = options.withDefaults(colors)
+
+	scale := float64(pngOptions.Scale)
+	// font pixels are kept whole so the text stays sharp
+	fontPixel := math.Max(math.Round(options.TextSize*scale), 1)
+	codeSide := float64(code.Bounds().Dx())
+	layout := getFrameLayout(options, codeSide, scale, fontPixel)
+
+	img := image.NewRGBA(image.Rect(0, 0, int(math.Ceil(layout.Width)), int(math.Ceil(layout.Height))))
+	drawFrameBox(img, options, options.Border*scale, options.CornerRadius*scale)
+
+	drawLines := func(lines []string, y float64) {
+		for index, line := range lines {
+			x := math.Round(layout.lineX(line))
+			lineY := math.Round(y + float64(index*FONT_LEADING)*fontPixel + fontPixel)
+			textPixels(line, func(px int, py int) {
+				corner := image.Point{int(x + float64(px)*fontPixel), int(lineY + float64(py)*fontPixel)}
+				pixel := image.Rectangle{corner, corner.Add(image.Point{int(fontPixel), int(fontPixel)})}
+				draw.Draw(img, pixel, &image.Uniform{options.TextColor}, image.Point{}, draw.Over)
+			})
+		}
+	}
+	drawLines(options.Above, layout.AboveY)
+	drawLines(options.Below, layout.BelowY)
+
+	codeAt := image.Point{int(math.Round(layout.CodeX)), int(math.Round(layout.CodeY))}
+	draw.Draw(img, code.Bounds().Add(codeAt), code, image.Point{}, draw.Over)
+	return img, nil
+}
+
+func RenderFramedPNG(w io.Writer, QRArray [][]uint8, pngOptions PNGOptions, options FrameOptions) error {
+	img, err := DrawFramed(QRArray, pngOptions, options)
+	if err != nil {
+		return err
+	}
+	var chunks [][]byte
+	if pngOptions.DPI > 0 {
+		chunks = append(chunks, physChunk(pngOptions.DPI))
+	}
+	if pngOptions.Metadata != nil {
+		chunks = append(chunks, metadataChunks(*pngOptions.Metadata)...)
+	}
+	return encodePNG(w, img, chunks...)
+}
+
+// svgTextPath returns a path with a square for every font pixel of the lines
+func svgTextPath(lines []string, y float64, layout frameLayout) string {
+	var path strings.Builder
+	for index, line := range lines {
+		x := layout.lineX(line)
+		lineY := y + float64(index*FONT_LEADING+1)*layout.FontPixel
+		textPixels(line, func(px int, py int) {
+			path.WriteString(svgSquarePath(x+float64(px)*layout.FontPixel, lineY+float64(py)*layout.FontPixel, layout.FontPixel))
+		})
+	}
+	return path.String()
+}
+
+// WriteFramedSVG writes the code like WriteSVG inside the frame, the text is drawn with
+// paths so the SVG does not depend on the fonts of the viewer. The quiet zone must have at
+// least FRAME_MIN_QUIET_ZONE modules.
+func WriteFramedSVG(w io.Writer, QRArray [][]uint8, svgOptions SVGOptions, options FrameOptions) error {
+	if err := checkFrameOptions(options, svgOptions.QuietZone); err != nil {
+		return err
+	}
+	colors, err := checkSVGOptions(&svgOptions)
+	if err != nil {
+		return err
+	}
+	options = options.withDefaults(colors)
+
+	codeSide := float64(len(QRArray) + 2*svgOptions.QuietZone)
+	layout := getFrameLayout(options, codeSide, 1, options.TextSize)
+
+	buffer := bufio.NewWriter(w)
+	fmt.Fprintf(buffer, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %g %g" width="%g" height="%g">`+"\n",
+		layout.Width, layout.Height, layout.Width*svgOptions.ModuleSize, layout.Height*svgOptions.ModuleSize)
+
+	radius := math.Min(options.CornerRadius, math.Min(layout.Width, layout.Height)/2)
+	fmt.Fprintf(buffer, `<rect width="%g" height="%g" rx="%g" %s/>`+"\n", layout.Width, layout.Height, radius, svgFill(options.Background))
+	if options.Border > 0 {
+		// the border is drawn inside the frame, like in the PNG
+		half := options.Border / 2
+		hex, opacity := svgColor(options.BorderColor)
+		fmt.Fprintf(buffer, `<rect x="%g" y="%g" width="%g" height="%g" rx="%g" fill="none" stroke="%s" stroke-opacity="%.3g" stroke-width="%g"/>`+"\n",
+			half, half, layout.Width-options.Border, layout.Height-options.Border, math.Max(radius-half, 0), hex, opacity, options.Border)
+	}
+
+	if text := svgTextPath(options.Above, layout.AboveY, layout) + svgTextPath(options.Below, layout.BelowY, layout); text != "" {
+		fmt.Fprintf(buffer, `<path d="%s" shape-rendering="crispEdges" %s/>`+"\n", text, svgFill(options.TextColor))
+	}
+
+	rendering := ""
+	if svgOptions.Style == nil {
+		rendering = ` shape-rendering="crispEdges"`
+	}
+	fmt.Fprintf(buffer, `<g transform="translate(%g %g)"%s>`+"\n", layout.CodeX, layout.CodeY, rendering)
+	if err := writeSVGCode(buffer, QRArray, svgOptions, colors); err != nil {
+		return err
+	}
+	buffer.WriteString("</g>\n</svg>\n")
+	return buffer.Flush()
+}
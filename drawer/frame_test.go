@@ -0,0 +1,22 @@
+package drawer
+
+import (
+	"io"
+	"testing"
+)
+
+func TestFrameQuietZone(t *testing.T) {
+	QRArray := randomMatrix(21, 4)
+	frame := FrameOptions{Above: []string{"SCAN ME"}, Border: 1}
+	for quietZone := range FRAME_MIN_QUIET_ZONE + 2 {
+		wantErr := quietZone < FRAME_MIN_QUIET_ZONE
+		err := RenderFramedPNG(io.Discard, QRArray, PNGOptions{Scale: 2, QuietZone: quietZone}, frame)
+		if (err != nil) != wantErr {
+			t.Errorf("PNG with a quiet zone of %d: got error %v, want error %v", quietZone, err, wantErr)
+		}
+		err = WriteFramedSVG(io.Discard, QRArray, SVGOptions{QuietZone: quietZone}, frame)
+		if (err != nil) != wantErr {
+			t.Errorf("SVG with a quiet zone of %d: got error %v, want error %v", quietZone, err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package drawer
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+)
+
+// GalleryEntry is one code of the gallery with the lines of text drawn under it,
+// when Heatmap is set the modules are tinted by its values
+type GalleryEntry struct {
+	QRArray [][]uint8
+	Lines   []string
+	Heatmap [][]float64
+}
+
+type GalleryOptions struct {
+	Scale     int // pixels per module
+	QuietZone int // modules of margin around every code
+	Columns   int // codes per row, 0 puts all of them in one row
+}
+
+var heatmapColor = color.RGBA{230, 30, 30, 255}
+
+// DrawHeatmap draws the code with every module tinted by its heat, the hottest module
+// of the heatmap is fully tinted and modules without heat are left as they are
+func DrawHeatmap(QRArray [][]uint8, heatmap [][]float64, scale int, quietZone int) (*image.RGBA, error) {
+	if err := checkBitmapParams(scale, quietZone); err != nil {
+		return nil, err
+	}
+	if len(heatmap) != len(QRArray) {
+		return nil, errors.New("heatmap does not match the matrix")
+	}
+	hottest := 0.0
+	for i := range heatmap {
+		if len(heatmap[i]) != len(QRArray[i]) {
+			return nil, errors.New("heatmap does not match the matrix")
+		}
+		for _, heat := range heatmap[i] {
+			hottest = max(hottest, heat)
+		}
+	}
+
+	img := drawQRImage(QRArray, scale, quietZone)
+	if hottest == 0 {
+		return img, nil
+	}
+	for i := range heatmap {
+		for j, heat := range heatmap[i] {
+			if heat <= 0 {
+				continue
+			}
+			// dark modules keep some of their color so the code can still be seen under the tint
+			coverage := 0.75 * heat / hottest
+			for y := (i + quietZone) * scale; y < (i+quietZone+1)*scale; y++ {
+				for x := (j + quietZone) * scale; x < (j+quietZone+1)*scale; x++ {
+					blendPixel(img, x, y, heatmapColor, coverage)
+				}
+			}
+		}
+	}
+	return img, nil
+}
+
+// DrawGallery draws the codes in a grid with their lines of text under them, the text
+// uses the embedded font with a pixel of half a module
+func DrawGallery(entries []GalleryEntry, options GalleryOptions) (*image.RGBA, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("the gallery is empty")
+	}
+	if options.Scale == 0 {
+		options.Scale = defaultCellSize
+	}
+	if err := checkBitmapParams(options.Scale, options.QuietZone); err != nil {
+		return nil, err
+	}
+	columns := options.Columns
+	if columns <= 0 || columns > len(entries) {
+		columns = len(entries)
+	}
+	rows := (len(entries) + columns - 1) / columns
+	fontPixel := max(options.Scale/2, 1)
+
+	// every cell fits the biggest code and the longest text
+	cellWidth, codeHeight, textLines := 0, 0, 0
+	for _, entry := range entries {
+		side := bitmapSize(entry.QRArray, options.Scale, options.QuietZone)
+		cellWidth = max(cellWidth, side, (maxTextWidth(entry.Lines)+2*FONT_ADVANCE)*fontPixel)
+		codeHeight = max(codeHeight, side)
+		textLines = max(textLines, len(entry.Lines))
+	}
+	cellHeight := codeHeight + textLines*FONT_LEADING*fontPixel
+
+	img := image.NewRGBA(image.Rect(0, 0, columns*cellWidth, rows*cellHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.White}, image.Point{}, draw.Src)
+	for index, entry := range entries {
+		cellX := index % columns * cellWidth
+		cellY := index / columns * cellHeight
+
+		var code *image.RGBA
+		if entry.Heatmap != nil {
+			heatmap, err := DrawHeatmap(entry.QRArray, entry.Heatmap, options.Scale, options.QuietZone)
+			if err != nil {
+				return nil, err
+			}
+			code = heatmap
+		} else {
+			code = drawQRImage(entry.QRArray, options.Scale, options.QuietZone)
+		}
+		codeAt := image.Point{cellX + (cellWidth-code.Bounds().Dx())/2, cellY}
+		draw.Draw(img, code.Bounds().Add(codeAt), code, image.Point{}, draw.Src)
+
+		for lineIndex, line := range entry.Lines {
+			lineX := cellX + (cellWidth-textWidth(line)*fontPixel)/2
+			lineY := cellY + codeHeight + (lineIndex*FONT_LEADING+1)*fontPixel
+			textPixels(line, func(px int, py int) {
+				corner := image.Point{lineX + px*fontPixel, lineY + py*fontPixel}
+				pixel := image.Rectangle{corner, corner.Add(image.Point{fontPixel, fontPixel})}
+				draw.Draw(img, pixel, &image.Uniform{color.Black}, image.Point{}, draw.Src)
+			})
+		}
+	}
+	return img, nil
+}
+
+func RenderGalleryPNG(w io.Writer, entries []GalleryEntry, options GalleryOptions) error {
+	img, err := DrawGallery(entries, options)
+	if err != nil {
+		return err
+	}
+	return encodePNG(w, img)
+}
@@ -0,0 +1,123 @@
+package drawer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type GCodeMode uint8
+
+const (
+	GCodeMode_Raster GCodeMode = iota
+	GCodeMode_Contour
+)
+
+type GCodeOptions struct {
+	Mode        GCodeMode
+	ModuleSize  float64 // in mm
+	LineSpacing float64 // distance between raster lines in mm
+	FeedRate    int     // engraving speed in mm/min
+	TravelRate  int     // speed of the moves with the laser off in mm/min
+	Power       int     // spindle value used while engraving, 0 to 1000 on most laser firmwares
+}
+
+func (options *GCodeOptions) setDefaults() error {
+	if options.ModuleSize == 0 {
+		options.ModuleSize = 1
+	}
+	if options.LineSpacing == 0 {
+		options.LineSpacing = 0.1
+	}
+	if options.FeedRate == 0 {
+		options.FeedRate = 1000
+	}
+	if options.TravelRate == 0 {
+		options.TravelRate = 3000
+	}
+	if options.Power == 0 {
+		options.Power = 1000
+	}
+	if options.ModuleSize < 0 || options.LineSpacing < 0 || options.FeedRate < 0 || options.TravelRate < 0 || options.Power < 0 {
+		return errors.New("gcode options can not be negative")
+	}
+	return nil
+}
+
+// WriteGCode writes a laser toolpath for the code, the origin is the lower left
+// corner of the code. Raster mode fills every dark run line by line, contour mode
+// only traces the border of every dark region.
+func WriteGCode(w io.Writer, QRArray [][]uint8, options GCodeOptions) error {
+	if err := options.setDefaults(); err != nil {
+		return err
+	}
+
+	buffer := bufio.NewWriter(w)
+	buffer.WriteString("G21 ; mm\nG90 ; absolute positions\n")
+	// M4 turns off the laser on G0 moves and scales power with the speed on firmwares with laser mode
+	buffer.WriteString("M4 S0\n")
+
+	switch options.Mode {
+	case GCodeMode_Raster:
+		writeGCodeRaster(buffer, QRArray, options)
+	case GCodeMode_Contour:
+		writeGCodeContour(buffer, QRArray, options)
+	default:
+		return errors.New("unknown gcode mode")
+	}
+
+	buffer.WriteString("M5\n")
+	fmt.Fprintf(buffer, "G0 X0 Y0 F%d\n", options.TravelRate)
+	buffer.WriteString("M2\n")
+	return buffer.Flush()
+}
+
+func writeGCodeRaster(buffer *bufio.Writer, QRArray [][]uint8, options GCodeOptions) {
+	size := len(QRArray)
+	height := float64(size) * options.ModuleSize
+	lines := int(height / options.LineSpacing)
+	leftToRight := true
+
+	for line := range lines {
+		// engrave in the middle of the line so it stays inside the modules
+		y := height - (float64(line)+0.5)*options.LineSpacing
+		row := QRArray[size-1-int(y/options.ModuleSize)]
+
+		// merge adjacent dark modules so the laser does not stop between them
+		runs := darkRuns(row)
+
+		if !leftToRight {
+			for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+				runs[i], runs[j] = runs[j], runs[i]
+			}
+		}
+		for _, run := range runs {
+			from := float64(run[0]) * options.ModuleSize
+			to := float64(run[1]) * options.ModuleSize
+			if !leftToRight {
+				from, to = to, from
+			}
+			fmt.Fprintf(buffer, "G0 X%.3f Y%.3f F%d\n", from, y, options.TravelRate)
+			fmt.Fprintf(buffer, "G1 X%.3f S%d F%d\n", to, options.Power, options.FeedRate)
+		}
+		if len(runs) > 0 {
+			leftToRight = !leftToRight
+		}
+	}
+}
+
+func writeGCodeContour(buffer *bufio.Writer, QRArray [][]uint8, options GCodeOptions) {
+	size := len(QRArray)
+	for _, contour := range traceContours(QRArray) {
+		x := float64(contour[0].X) * options.ModuleSize
+		y := float64(size-contour[0].Y) * options.ModuleSize
+		fmt.Fprintf(buffer, "G0 X%.3f Y%.3f F%d\n", x, y, options.TravelRate)
+		for index := 1; index <= len(contour); index++ {
+			point := contour[index%len(contour)]
+			x = float64(point.X) * options.ModuleSize
+			y = float64(size-point.Y) * options.ModuleSize
+			fmt.Fprintf(buffer, "G1 X%.3f Y%.3f S%d F%d\n", x, y, options.Power, options.FeedRate)
+		}
+	}
+}
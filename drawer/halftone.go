@@ -0,0 +1,124 @@
+package drawer
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+// HALFTONE_SUBMODULES is the side of the grid every module is split in, only the
+// center of the grid carries the value of the module
+const HALFTONE_SUBMODULES = 3
+
+type HalftoneOptions struct {
+	Background image.Image    // stretched over the code, without the quiet zone
+	PixelSize  int            // pixels per submodule
+	QuietZone  int            // modules of margin on every side
+	Colors     *Colors        // nil uses DefaultColors
+	Roles      [][]ModuleRole // nil uses GetModuleRoles
+}
+
+// halftoneGrid returns for every submodule of the code if it is dark. The background
+// is dithered with Floyd-Steinberg, the centers of the data modules and the whole
+// function patterns are forced to their value and their error is spread around them,
+// so the picture is kept as much as possible.
+func halftoneGrid(QRArray [][]uint8, roles [][]ModuleRole, background image.Image) [][]bool {
+	size := len(QRArray) * HALFTONE_SUBMODULES
+	fill := ImageFill{Image: background}
+	levels := make([][]float64, size)
+	for y := range levels {
+		levels[y] = make([]float64, size)
+		for x := range levels[y] {
+			levels[y][x] = relativeLuminance(fill.ModuleColor(y, x, size))
+		}
+	}
+
+	grid := make([][]bool, size)
+	for y := range grid {
+		grid[y] = make([]bool, size)
+	}
+	spread := func(y int, x int, err float64) {
+		if y < size && x >= 0 && x < size {
+			levels[y][x] += err
+		}
+	}
+	for y := range size {
+		for x := range size {
+			i, j := y/HALFTONE_SUBMODULES, x/HALFTONE_SUBMODULES
+			isCenter := y%HALFTONE_SUBMODULES == HALFTONE_SUBMODULES/2 && x%HALFTONE_SUBMODULES == HALFTONE_SUBMODULES/2
+			if isCenter || roles[i][j].IsFunctionPattern() {
+				grid[y][x] = isDark(QRArray[i][j])
+			} else {
+				grid[y][x] = levels[y][x] < 0.5
+			}
+
+			value := 1.0
+			if grid[y][x] {
+				value = 0
+			}
+			err := levels[y][x] - value
+			spread(y, x+1, err*7/16)
+			spread(y+1, x-1, err*3/16)
+			spread(y+1, x, err*5/16)
+			spread(y+1, x+1, err*1/16)
+		}
+	}
+	return grid
+}
+
+// DrawHalftone draws a picture code, every module is a grid of HALFTONE_SUBMODULES
+// submodules where the center has the value of the module and the rest shows the
+// dithered background. Function patterns are drawn solid so the code can be found.
+func DrawHalftone(QRArray [][]uint8, options HalftoneOptions) (*image.Paletted, error) {
+	if options.Background == nil {
+		return nil, errors.New("halftone needs a background image")
+	}
+	if options.PixelSize == 0 {
+		options.PixelSize = 1
+	}
+	if err := checkBitmapParams(options.PixelSize, options.QuietZone); err != nil {
+		return nil, err
+	}
+	roles, err := getRoles(QRArray, options.Roles)
+	if err != nil {
+		return nil, err
+	}
+	colors, err := getColors(options.Colors)
+	if err != nil {
+		return nil, err
+	}
+
+	palette := color.Palette{colors.Light, colors.Dark}
+	if colors.QuietZone != nil && colors.QuietZone != colors.Light {
+		palette = append(palette, colors.QuietZone)
+	}
+	grid := halftoneGrid(QRArray, roles, options.Background)
+	quietZone := options.QuietZone * HALFTONE_SUBMODULES
+	side := (len(grid) + 2*quietZone) * options.PixelSize
+	img := image.NewPaletted(image.Rect(0, 0, side, side), palette)
+
+	for y := range side {
+		for x := range side {
+			i := y/options.PixelSize - quietZone
+			j := x/options.PixelSize - quietZone
+			switch {
+			case i < 0 || j < 0 || i >= len(grid) || j >= len(grid):
+				if len(palette) > 2 {
+					img.SetColorIndex(x, y, 2)
+				}
+			case grid[i][j]:
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	return img, nil
+}
+
+func RenderHalftonePNG(w io.Writer, QRArray [][]uint8, options HalftoneOptions) error {
+	img, err := DrawHalftone(QRArray, options)
+	if err != nil {
+		return err
+	}
+	return encodePNG(w, img)
+}
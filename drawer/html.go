@@ -0,0 +1,79 @@
+package drawer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type HTMLOptions struct {
+	ModuleSize int  // in px
+	QuietZone  int  // modules of margin around the code
+	UseTable   bool // use a table instead of a CSS grid, for email clients and old renderers
+}
+
+// WriteHTML writes the code as plain HTML elements with inline styles so it can be
+// embedded in a document without any image
+func WriteHTML(w io.Writer, QRArray [][]uint8, options HTMLOptions) error {
+	if options.ModuleSize == 0 {
+		options.ModuleSize = 4
+	}
+	if err := checkBitmapParams(options.ModuleSize, options.QuietZone); err != nil {
+		return err
+	}
+	if err := checkSquareMatrix(QRArray); err != nil {
+		return errors.New("invalid matrix: " + err.Error())
+	}
+
+	buffer := bufio.NewWriter(w)
+	if options.UseTable {
+		writeHTMLTable(buffer, QRArray, options)
+	} else {
+		writeHTMLGrid(buffer, QRArray, options)
+	}
+	return buffer.Flush()
+}
+
+func writeHTMLGrid(buffer *bufio.Writer, QRArray [][]uint8, options HTMLOptions) {
+	size := len(QRArray)
+	fmt.Fprintf(buffer, `<div style="display:inline-grid;grid-template-columns:repeat(%d,%dpx);grid-auto-rows:%dpx;padding:%dpx;background:#ffffff;line-height:0">`+"\n",
+		size, options.ModuleSize, options.ModuleSize, options.QuietZone*options.ModuleSize)
+	for i := range QRArray {
+		for j := 0; j < size; {
+			// runs of the same color are merged in a single element spanning the columns
+			dark := isDark(QRArray[i][j])
+			end := j
+			for end < size && isDark(QRArray[i][end]) == dark {
+				end++
+			}
+			if dark {
+				fmt.Fprintf(buffer, `<div style="grid-column:span %d;background:#000000"></div>`, end-j)
+			} else {
+				fmt.Fprintf(buffer, `<div style="grid-column:span %d"></div>`, end-j)
+			}
+			j = end
+		}
+		buffer.WriteString("\n")
+	}
+	buffer.WriteString("</div>\n")
+}
+
+func writeHTMLTable(buffer *bufio.Writer, QRArray [][]uint8, options HTMLOptions) {
+	fmt.Fprintf(buffer, `<table style="border-collapse:collapse;border-spacing:0;border:%dpx solid #ffffff;background:#ffffff">`+"\n",
+		options.QuietZone*options.ModuleSize)
+	lightCell := fmt.Sprintf(`<td style="width:%dpx;height:%dpx;padding:0"></td>`, options.ModuleSize, options.ModuleSize)
+	darkCell := fmt.Sprintf(`<td style="width:%dpx;height:%dpx;padding:0;background:#000000"></td>`, options.ModuleSize, options.ModuleSize)
+	for i := range QRArray {
+		buffer.WriteString("<tr>")
+		for _, cell := range QRArray[i] {
+			if isDark(cell) {
+				buffer.WriteString(darkCell)
+			} else {
+				buffer.WriteString(lightCell)
+			}
+		}
+		buffer.WriteString("</tr>\n")
+	}
+	buffer.WriteString("</table>\n")
+}
@@ -0,0 +1,71 @@
+package drawer
+
+import (
+	"image"
+	"image/color"
+)
+
+// QRImage implements image.PalettedImage directly over the QR matrix, every pixel
+// is computed when it is asked so big prints only keep the matrix in memory.
+// Index 0 of the palette is used for light modules and index 1 for dark ones, when
+// the palette has a third color it is used for the quiet zone.
+type QRImage struct {
+	QRArray   [][]uint8
+	Scale     int // pixels per module
+	QuietZone int // modules of margin on every side
+	Palette   color.Palette
+}
+
+func NewQRImage(QRArray [][]uint8, scale int, quietZone int) *QRImage {
+	if scale < 1 {
+		scale = 1
+	}
+	if quietZone < 0 {
+		quietZone = 0
+	}
+	return &QRImage{
+		QRArray:   QRArray,
+		Scale:     scale,
+		QuietZone: quietZone,
+		Palette:   color.Palette{color.White, color.Black},
+	}
+}
+
+func (QRImg *QRImage) SetColors(colors Colors) {
+	QRImg.Palette = color.Palette{colors.Light, colors.Dark}
+	if colors.QuietZone != nil && colors.QuietZone != colors.Light {
+		QRImg.Palette = append(QRImg.Palette, colors.QuietZone)
+	}
+}
+
+func (QRImg *QRImage) inQuietZone(x int, y int) bool {
+	i := y/QRImg.Scale - QRImg.QuietZone
+	j := x/QRImg.Scale - QRImg.QuietZone
+	return i < 0 || j < 0 || i >= len(QRImg.QRArray) || j >= len(QRImg.QRArray)
+}
+
+func (QRImg *QRImage) ColorModel() color.Model {
+	return QRImg.Palette
+}
+
+func (QRImg *QRImage) Bounds() image.Rectangle {
+	size := bitmapSize(QRImg.QRArray, QRImg.Scale, QRImg.QuietZone)
+	return image.Rect(0, 0, size, size)
+}
+
+func (QRImg *QRImage) ColorIndexAt(x int, y int) uint8 {
+	if !(image.Point{x, y}.In(QRImg.Bounds())) {
+		return 0
+	}
+	if len(QRImg.Palette) > 2 && QRImg.inQuietZone(x, y) {
+		return 2
+	}
+	if pixelIsDark(QRImg.QRArray, QRImg.Scale, QRImg.QuietZone, x, y) {
+		return 1
+	}
+	return 0
+}
+
+func (QRImg *QRImage) At(x int, y int) color.Color {
+	return QRImg.Palette[QRImg.ColorIndexAt(x, y)]
+}
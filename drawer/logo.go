@@ -0,0 +1,138 @@
+package drawer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Logo is drawn on the center of styled codes. The modules under it are lost, so
+// Modules should not be bigger than what the error correction level can recover.
+type Logo struct {
+	Image           image.Image
+	Modules         int     // side of the centered square taken by the logo, in modules
+	Padding         float64 // margin between the border of the square and the image, in modules
+	ClearBackground bool    // remove the modules under the logo and fill the square with the light color
+}
+
+func LoadLogo(r io.Reader) (image.Image, error) {
+	return png.Decode(r)
+}
+
+// check returns an error when the logo can not be drawn on a code of the given size
+func (logo *Logo) check(size int) error {
+	if logo == nil {
+		return nil
+	}
+	if logo.Image == nil {
+		return errors.New("the logo has no image")
+	}
+	if logo.Modules <= 0 || logo.Modules > size {
+		return fmt.Errorf("the logo must take between 1 and %d modules, not %d", size, logo.Modules)
+	}
+	if logo.Padding < 0 || 2*logo.Padding >= float64(logo.Modules) {
+		return errors.New("the logo padding leaves no room for the image")
+	}
+	return nil
+}
+
+// logoArea returns the modules covered by the logo, as [start, end) rows and columns
+func (logo *Logo) logoArea(size int) image.Rectangle {
+	start := (size - logo.Modules) / 2
+	return image.Rect(start, start, start+logo.Modules, start+logo.Modules)
+}
+
+// covers tells if the logo hides the module (i, j)
+func (logo *Logo) covers(size int, i int, j int) bool {
+	return logo != nil && logo.ClearBackground && image.Point{j, i}.In(logo.logoArea(size))
+}
+
+// imageRect returns the rectangle, in modules, where the image is drawn keeping its aspect ratio
+func (logo *Logo) imageRect(size int) (float64, float64, float64, float64) {
+	area := logo.logoArea(size)
+	side := float64(logo.Modules) - 2*logo.Padding
+	bounds := logo.Image.Bounds()
+	width, height := side, side
+	if bounds.Dx() > bounds.Dy() {
+		height = side * float64(bounds.Dy()) / float64(bounds.Dx())
+	} else if bounds.Dy() > bounds.Dx() {
+		width = side * float64(bounds.Dx()) / float64(bounds.Dy())
+	}
+	x := float64(area.Min.X) + (float64(logo.Modules)-width)/2
+	y := float64(area.Min.Y) + (float64(logo.Modules)-height)/2
+	return x, y, width, height
+}
+
+// drawScaled draws src scaled into dst, every pixel is the average of the source
+// pixels it covers so big logos are not aliased when they are reduced
+func drawScaled(dst *image.RGBA, x float64, y float64, width float64, height float64, src image.Image) {
+	bounds := src.Bounds()
+	scaleX := float64(bounds.Dx()) / width
+	scaleY := float64(bounds.Dy()) / height
+
+	for py := int(math.Floor(y)); py < int(math.Ceil(y+height)); py++ {
+		for px := int(math.Floor(x)); px < int(math.Ceil(x+width)); px++ {
+			x0 := bounds.Min.X + int((float64(px)-x)*scaleX)
+			y0 := bounds.Min.Y + int((float64(py)-y)*scaleY)
+			x1 := max(bounds.Min.X+int((float64(px+1)-x)*scaleX), x0+1)
+			y1 := max(bounds.Min.Y+int((float64(py+1)-y)*scaleY), y0+1)
+
+			var r, g, b, a, count uint64
+			for sy := max(y0, bounds.Min.Y); sy < min(y1, bounds.Max.Y); sy++ {
+				for sx := max(x0, bounds.Min.X); sx < min(x1, bounds.Max.X); sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					count++
+				}
+			}
+			if count == 0 {
+				continue
+			}
+			average := color.RGBA64{uint16(r / count), uint16(g / count), uint16(b / count), uint16(a / count)}
+			blendPixel(dst, px, py, average, 1)
+		}
+	}
+}
+
+func drawLogo(QRImage *image.RGBA, size int, scale int, quietZone int, colors Colors, logo *Logo) {
+	moduleSize := float64(scale)
+	if logo.ClearBackground {
+		area := logo.logoArea(size)
+		offset := quietZone * scale
+		clearArea := image.Rect(area.Min.X*scale+offset, area.Min.Y*scale+offset, area.Max.X*scale+offset, area.Max.Y*scale+offset)
+		for y := clearArea.Min.Y; y < clearArea.Max.Y; y++ {
+			for x := clearArea.Min.X; x < clearArea.Max.X; x++ {
+				QRImage.Set(x, y, colors.Light)
+			}
+		}
+	}
+	x, y, width, height := logo.imageRect(size)
+	offset := float64(quietZone)
+	drawScaled(QRImage, (x+offset)*moduleSize, (y+offset)*moduleSize, width*moduleSize, height*moduleSize, logo.Image)
+}
+
+// svgLogo returns the elements that draw the logo, the image is embedded as a PNG
+func svgLogo(size int, quietZone int, colors Colors, logo *Logo) (string, error) {
+	var elements string
+	offset := float64(quietZone)
+	if logo.ClearBackground {
+		area := logo.logoArea(size)
+		elements += fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" %s/>`+"\n",
+			area.Min.X+quietZone, area.Min.Y+quietZone, logo.Modules, logo.Modules, svgFill(colors.Light))
+	}
+
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, logo.Image); err != nil {
+		return "", err
+	}
+	x, y, width, height := logo.imageRect(size)
+	elements += fmt.Sprintf(`<image x="%g" y="%g" width="%g" height="%g" href="data:image/png;base64,%s"/>`+"\n",
+		x+offset, y+offset, width, height, base64.StdEncoding.EncodeToString(encoded.Bytes()))
+	return elements, nil
+}
@@ -0,0 +1,45 @@
+package drawer
+
+import (
+	"image"
+	"io"
+	"testing"
+)
+
+func TestLogoOptions(t *testing.T) {
+	QRArray := randomMatrix(25, 2)
+	roles := make([][]ModuleRole, len(QRArray))
+	for i := range roles {
+		roles[i] = make([]ModuleRole, len(QRArray))
+	}
+	logoImage := image.NewRGBA(image.Rect(0, 0, 8, 4))
+
+	tests := []struct {
+		name    string
+		logo    *Logo
+		wantErr bool
+	}{
+		{"no logo", nil, false},
+		{"logo", &Logo{Image: logoImage, Modules: 7, Padding: 0.5, ClearBackground: true}, false},
+		{"whole code", &Logo{Image: logoImage, Modules: 25}, false},
+		{"no image", &Logo{Modules: 7}, true},
+		{"no modules", &Logo{Image: logoImage}, true},
+		{"negative modules", &Logo{Image: logoImage, Modules: -3, ClearBackground: true}, true},
+		{"bigger than the code", &Logo{Image: logoImage, Modules: 26, ClearBackground: true}, true},
+		{"padding covers the logo", &Logo{Image: logoImage, Modules: 4, Padding: 2}, true},
+		{"negative padding", &Logo{Image: logoImage, Modules: 4, Padding: -1}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			style := &Style{Logo: test.logo}
+			err := RenderPNG(io.Discard, QRArray, PNGOptions{Scale: 2, Style: style, Roles: roles})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("PNG: got error %v, want error %v", err, test.wantErr)
+			}
+			err = WriteSVG(io.Discard, QRArray, SVGOptions{Style: style, Roles: roles})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("SVG: got error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package drawer
+
+import (
+	"QRCodeGenerator/generator"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MatrixExport is the JSON representation of a finished QR code,
+// rows are written as strings of 0 (light) and 1 (dark) so they diff nicely
+type MatrixExport struct {
+	Version      int      `json:"version"`
+	Size         int      `json:"size"`
+	ErrorLevel   string   `json:"errorLevel"`
+	MaskPattern  int      `json:"maskPattern"`
+	EncodingMode string   `json:"encodingMode"`
+	Rows         []string `json:"rows"`
+}
+
+func isDark(cell uint8) bool {
+	return cell == BLACK_COLOR
+}
+
+func cellFromBool(dark bool) uint8 {
+	if dark {
+		return BLACK_COLOR
+	}
+	return WHITE_COLOR
+}
+
+// darkRuns returns the [start, end) columns of every run of dark modules in the row
+func darkRuns(row []uint8) [][2]int {
+	var runs [][2]int
+	for j := 0; j < len(row); j++ {
+		if !isDark(row[j]) {
+			continue
+		}
+		start := j
+		for j < len(row) && isDark(row[j]) {
+			j++
+		}
+		runs = append(runs, [2]int{start, j})
+	}
+	return runs
+}
+
+func newMatrix(size int) [][]uint8 {
+	QRArray := make([][]uint8, size)
+	for i := range size {
+		QRArray[i] = make([]uint8, size)
+	}
+	return QRArray
+}
+
+func checkSquareMatrix(QRArray [][]uint8) error {
+	if len(QRArray) == 0 {
+		return errors.New("empty matrix")
+	}
+	for _, row := range QRArray {
+		if len(row) != len(QRArray) {
+			return errors.New("matrix is not square")
+		}
+	}
+	return nil
+}
+
+// WriteMatrixText writes one line per row using the given strings for dark and light modules
+func WriteMatrixText(w io.Writer, QRArray [][]uint8, dark string, light string) error {
+	var builder strings.Builder
+	for i := range QRArray {
+		for _, cell := range QRArray[i] {
+			if isDark(cell) {
+				builder.WriteString(dark)
+			} else {
+				builder.WriteString(light)
+			}
+		}
+		builder.WriteString("\n")
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+func WriteMatrixCSV(w io.Writer, QRArray [][]uint8) error {
+	csvWriter := csv.NewWriter(w)
+	record := make([]string, len(QRArray))
+	for i := range QRArray {
+		for j, cell := range QRArray[i] {
+			if isDark(cell) {
+				record[j] = "1"
+			} else {
+				record[j] = "0"
+			}
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func WriteMatrixJSON(w io.Writer, QRArray [][]uint8, QRversion generator.QRCodeInfo) error {
+	export := MatrixExport{
+		Version:      QRversion.Version,
+		Size:         len(QRArray),
+		ErrorLevel:   QRversion.ErrorLevel.Letter(),
+		MaskPattern:  int(QRversion.MaskPatern),
+		EncodingMode: QRversion.EncodingMode.String(),
+		Rows:         make([]string, len(QRArray)),
+	}
+	for i := range QRArray {
+		var row strings.Builder
+		for _, cell := range QRArray[i] {
+			if isDark(cell) {
+				row.WriteByte('1')
+			} else {
+				row.WriteByte('0')
+			}
+		}
+		export.Rows[i] = row.String()
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+// ReadMatrixText reads back the output of WriteMatrixText, the light string must have
+// the same width as the dark one, anything that is not the dark string is taken as light
+// so trimmed trailing spaces are not a problem
+func ReadMatrixText(r io.Reader, dark string) ([][]uint8, error) {
+	if dark == "" {
+		return nil, errors.New("dark string can not be empty")
+	}
+	var rows [][]bool
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" && len(rows) == 0 {
+			continue
+		}
+		var row []bool
+		runes := []rune(line)
+		darkRunes := []rune(dark)
+		for i := 0; i < len(runes); {
+			if i+len(darkRunes) <= len(runes) && string(runes[i:i+len(darkRunes)]) == dark {
+				row = append(row, true)
+			} else {
+				row = append(row, false)
+			}
+			i += len(darkRunes)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	//drop empty lines at the end
+	for len(rows) > 0 && len(rows[len(rows)-1]) == 0 {
+		rows = rows[:len(rows)-1]
+	}
+
+	QRArray := newMatrix(len(rows))
+	for i, row := range rows {
+		if len(row) > len(rows) {
+			return nil, errors.New("line " + strconv.Itoa(i+1) + " is longer than the matrix")
+		}
+		for j := range QRArray[i] {
+			QRArray[i][j] = cellFromBool(j < len(row) && row[j])
+		}
+	}
+	return QRArray, checkSquareMatrix(QRArray)
+}
+
+func ReadMatrixCSV(r io.Reader) ([][]uint8, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	QRArray := newMatrix(len(records))
+	for i, record := range records {
+		if len(record) != len(records) {
+			return nil, errors.New("matrix is not square")
+		}
+		for j, value := range record {
+			switch strings.TrimSpace(value) {
+			case "1":
+				QRArray[i][j] = BLACK_COLOR
+			case "0":
+				QRArray[i][j] = WHITE_COLOR
+			default:
+				return nil, errors.New("invalid module value: " + value)
+			}
+		}
+	}
+	return QRArray, checkSquareMatrix(QRArray)
+}
+
+// ReadMatrixJSON returns the matrix and the QR info needed to render it again,
+// fields that are not part of the export (like the encoded data) are left empty
+func ReadMatrixJSON(r io.Reader) ([][]uint8, generator.QRCodeInfo, error) {
+	var export MatrixExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, generator.QRCodeInfo{}, err
+	}
+
+	errorLevel, err := generator.ParseErrorLevel(export.ErrorLevel)
+	if err != nil {
+		return nil, generator.QRCodeInfo{}, err
+	}
+	encodingMode, err := generator.ParseEncodingMode(export.EncodingMode)
+	if err != nil {
+		return nil, generator.QRCodeInfo{}, err
+	}
+	if export.MaskPattern < 0 || export.MaskPattern > int(generator.MaskPattern_7) {
+		return nil, generator.QRCodeInfo{}, errors.New("invalid mask pattern: " + strconv.Itoa(export.MaskPattern))
+	}
+
+	QRArray := newMatrix(len(export.Rows))
+	for i, row := range export.Rows {
+		if len(row) != len(export.Rows) {
+			return nil, generator.QRCodeInfo{}, errors.New("matrix is not square")
+		}
+		for j := range row {
+			switch row[j] {
+			case '1':
+				QRArray[i][j] = BLACK_COLOR
+			case '0':
+				QRArray[i][j] = WHITE_COLOR
+			default:
+				return nil, generator.QRCodeInfo{}, errors.New("invalid module value: " + string(row[j]))
+			}
+		}
+	}
+	if err := checkSquareMatrix(QRArray); err != nil {
+		return nil, generator.QRCodeInfo{}, err
+	}
+	if export.Size != 0 && export.Size != len(QRArray) {
+		return nil, generator.QRCodeInfo{}, errors.New("size does not match the rows")
+	}
+
+	QRversion := generator.QRCodeInfo{
+		Version:      export.Version,
+		Size:         len(QRArray),
+		ErrorLevel:   errorLevel,
+		MaskPatern:   generator.MaskPattern(export.MaskPattern),
+		EncodingMode: encodingMode,
+	}
+	return QRArray, QRversion, nil
+}
@@ -0,0 +1,166 @@
+package drawer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// bitmapSize returns the side in pixels of a matrix drawn with scale pixels
+// per module and quietZone modules of margin on every side
+func bitmapSize(QRArray [][]uint8, scale int, quietZone int) int {
+	return (len(QRArray) + 2*quietZone) * scale
+}
+
+// pixelIsDark tells if the pixel (x, y) of the scaled bitmap falls on a dark module
+func pixelIsDark(QRArray [][]uint8, scale int, quietZone int, x int, y int) bool {
+	i := y/scale - quietZone
+	j := x/scale - quietZone
+	if i < 0 || j < 0 || i >= len(QRArray) || j >= len(QRArray[i]) {
+		return false
+	}
+	return isDark(QRArray[i][j])
+}
+
+func checkBitmapParams(scale int, quietZone int) error {
+	if scale < 1 {
+		return errors.New("scale must be at least 1")
+	}
+	if quietZone < 0 {
+		return errors.New("quiet zone can not be negative")
+	}
+	return nil
+}
+
+// packRow packs a row of the bitmap 8 pixels per byte, msbFirst selects the
+// bit order (PBM uses the most significant bit first, XBM the least significant)
+func packRow(QRArray [][]uint8, scale int, quietZone int, y int, msbFirst bool) []byte {
+	size := bitmapSize(QRArray, scale, quietZone)
+	row := make([]byte, (size+7)/8)
+	for x := range size {
+		if !pixelIsDark(QRArray, scale, quietZone, x, y) {
+			continue
+		}
+		if msbFirst {
+			row[x/8] |= 0x80 >> (x % 8)
+		} else {
+			row[x/8] |= 1 << (x % 8)
+		}
+	}
+	return row
+}
+
+// WritePBM writes a bitmap where 1 is a dark module, binary selects P4 over the plain P1 format
+func WritePBM(w io.Writer, QRArray [][]uint8, scale int, quietZone int, binary bool) error {
+	if err := checkBitmapParams(scale, quietZone); err != nil {
+		return err
+	}
+	size := bitmapSize(QRArray, scale, quietZone)
+	buffer := bufio.NewWriter(w)
+
+	if binary {
+		fmt.Fprintf(buffer, "P4\n%d %d\n", size, size)
+		for y := range size {
+			buffer.Write(packRow(QRArray, scale, quietZone, y, true))
+		}
+		return buffer.Flush()
+	}
+
+	fmt.Fprintf(buffer, "P1\n%d %d\n", size, size)
+	for y := range size {
+		for x := range size {
+			// lines should not be longer than 70 characters
+			if x > 0 && x%35 == 0 {
+				buffer.WriteByte('\n')
+			} else if x > 0 {
+				buffer.WriteByte(' ')
+			}
+			if pixelIsDark(QRArray, scale, quietZone, x, y) {
+				buffer.WriteByte('1')
+			} else {
+				buffer.WriteByte('0')
+			}
+		}
+		buffer.WriteByte('\n')
+	}
+	return buffer.Flush()
+}
+
+// WritePGM writes a graymap with a max value of 1 so it stays a two level image,
+// binary selects P5 over the plain P2 format
+func WritePGM(w io.Writer, QRArray [][]uint8, scale int, quietZone int, binary bool) error {
+	if err := checkBitmapParams(scale, quietZone); err != nil {
+		return err
+	}
+	size := bitmapSize(QRArray, scale, quietZone)
+	buffer := bufio.NewWriter(w)
+
+	if binary {
+		fmt.Fprintf(buffer, "P5\n%d %d\n1\n", size, size)
+	} else {
+		fmt.Fprintf(buffer, "P2\n%d %d\n1\n", size, size)
+	}
+
+	for y := range size {
+		for x := range size {
+			// in PGM 0 is black
+			var value byte = 1
+			if pixelIsDark(QRArray, scale, quietZone, x, y) {
+				value = 0
+			}
+			if binary {
+				buffer.WriteByte(value)
+				continue
+			}
+			if x > 0 && x%35 == 0 {
+				buffer.WriteByte('\n')
+			} else if x > 0 {
+				buffer.WriteByte(' ')
+			}
+			buffer.WriteByte('0' + value)
+		}
+		if !binary {
+			buffer.WriteByte('\n')
+		}
+	}
+	return buffer.Flush()
+}
+
+// WriteXBM writes the bitmap as C source, name is used as prefix for the defines and the array
+func WriteXBM(w io.Writer, QRArray [][]uint8, scale int, quietZone int, name string) error {
+	if err := checkBitmapParams(scale, quietZone); err != nil {
+		return err
+	}
+	if name == "" {
+		name = "qrcode"
+	}
+	size := bitmapSize(QRArray, scale, quietZone)
+	buffer := bufio.NewWriter(w)
+
+	fmt.Fprintf(buffer, "#define %s_width %d\n", name, size)
+	fmt.Fprintf(buffer, "#define %s_height %d\n", name, size)
+	fmt.Fprintf(buffer, "static unsigned char %s_bits[] = {\n", name)
+
+	written := 0
+	total := size * ((size + 7) / 8)
+	for y := range size {
+		for _, value := range packRow(QRArray, scale, quietZone, y, false) {
+			if written%12 == 0 {
+				buffer.WriteString("   ")
+			}
+			fmt.Fprintf(buffer, "0x%02x", value)
+			written++
+			if written < total {
+				buffer.WriteByte(',')
+			}
+			if written%12 == 0 || written == total {
+				buffer.WriteByte('\n')
+			} else {
+				buffer.WriteByte(' ')
+			}
+		}
+	}
+	buffer.WriteString("};\n")
+	return buffer.Flush()
+}
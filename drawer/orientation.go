@@ -0,0 +1,178 @@
+package drawer
+
+import (
+	"QRCodeGenerator/generator"
+	"QRCodeGenerator/utils"
+	"errors"
+)
+
+// Orientation tells how a matrix was turned into the normal symbol by NormalizeMatrix
+type Orientation struct {
+	Reversed bool // dark and light modules were swapped, like light marks on a dark surface
+	Mirrored bool // the symbol was a mirror image, like when it is read through glass
+	Rotation int  // quarter turns clockwise applied after the mirror
+}
+
+func (orientation Orientation) IsNormal() bool {
+	return orientation == Orientation{}
+}
+
+// invertMatrix returns the matrix with dark and light modules swapped, reversed symbols
+// are drawn from the normal matrix with ReversedColors so only NormalizeMatrix needs it
+func invertMatrix(QRArray [][]uint8) [][]uint8 {
+	inverted := newMatrix(len(QRArray))
+	for i := range inverted {
+		for j := range inverted[i] {
+			inverted[i][j] = cellFromBool(!isDark(QRArray[i][j]))
+		}
+	}
+	return inverted
+}
+
+// MirrorMatrix returns the matrix flipped left to right
+func MirrorMatrix(QRArray [][]uint8) [][]uint8 {
+	return mirrorGrid(QRArray)
+}
+
+// MirrorRoles flips the roles like MirrorMatrix, so mirrored symbols can be styled
+func MirrorRoles(roles [][]ModuleRole) [][]ModuleRole {
+	return mirrorGrid(roles)
+}
+
+func mirrorGrid[T any](grid [][]T) [][]T {
+	mirrored := make([][]T, len(grid))
+	for i := range grid {
+		mirrored[i] = make([]T, len(grid[i]))
+		for j := range grid[i] {
+			mirrored[i][len(grid[i])-1-j] = grid[i][j]
+		}
+	}
+	return mirrored
+}
+
+// rotateMatrix returns the matrix turned a quarter clockwise
+func rotateMatrix(QRArray [][]uint8) [][]uint8 {
+	size := len(QRArray)
+	rotated := newMatrix(size)
+	for i := range rotated {
+		for j := range rotated[i] {
+			rotated[i][j] = QRArray[size-1-j][i]
+		}
+	}
+	return rotated
+}
+
+// stripUniformBorder removes the rings of modules of the same color around the
+// symbol, like a quiet zone exported as part of the matrix
+func stripUniformBorder(QRArray [][]uint8) [][]uint8 {
+	for len(QRArray) > 21 {
+		size := len(QRArray)
+		color := isDark(QRArray[0][0])
+		for k := range size {
+			if isDark(QRArray[0][k]) != color || isDark(QRArray[size-1][k]) != color ||
+				isDark(QRArray[k][0]) != color || isDark(QRArray[k][size-1]) != color {
+				return QRArray
+			}
+		}
+		inner := make([][]uint8, size-2)
+		for i := range inner {
+			inner[i] = QRArray[i+1][1 : size-1]
+		}
+		QRArray = inner
+	}
+	return QRArray
+}
+
+// hasFinderAt tells if there is a finder pattern with its top left module at (i, j)
+func hasFinderAt(QRArray [][]uint8, i int, j int) bool {
+	for y := range 7 {
+		for x := range 7 {
+			ring := max(utils.Abs(y-3), utils.Abs(x-3))
+			if isDark(QRArray[i+y][j+x]) != (ring != 2) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// formatBits reads the two copies of the format information, most significant bit first
+func formatBits(QRArray [][]uint8) (uint16, uint16) {
+	size := len(QRArray)
+	var first, second uint16
+	add := func(value uint16, i int, j int) uint16 {
+		value <<= 1
+		if isDark(QRArray[i][j]) {
+			value |= 1
+		}
+		return value
+	}
+	// the same positions AddFormatVersion writes
+	for _, j := range []int{0, 1, 2, 3, 4, 5, 7} {
+		first = add(first, 8, j)
+	}
+	for j := size - 8; j < size; j++ {
+		first = add(first, 8, j)
+	}
+	for i := size - 1; i >= size-7; i-- {
+		second = add(second, i, 8)
+	}
+	for _, i := range []int{8, 7, 5, 4, 3, 2, 1, 0} {
+		second = add(second, i, 8)
+	}
+	return first, second
+}
+
+func isValidFormat(bits uint16) bool {
+	for _, masks := range generator.MaskPatternByErrorLevel {
+		for _, format := range masks {
+			if format == bits {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isNormalSymbol tells if the finder patterns and both copies of the format
+// information are where a symbol with the normal orientation has them
+func isNormalSymbol(QRArray [][]uint8) bool {
+	size := len(QRArray)
+	if size < 21 || !hasFinderAt(QRArray, 0, 0) || !hasFinderAt(QRArray, 0, size-7) || !hasFinderAt(QRArray, size-7, 0) {
+		return false
+	}
+	first, second := formatBits(QRArray)
+	return first == second && isValidFormat(first)
+}
+
+// NormalizeMatrix turns reversed, mirrored or rotated symbols into the normal one, like a
+// matrix sampled from a photo, and tells what was undone. Uniform borders around the
+// symbol, like a quiet zone, are removed. A transposed symbol has its finder patterns in
+// the same corners as the normal one, so the format information is used to tell them
+// apart. The matrix readers do not call it, they return the modules as they were written.
+func NormalizeMatrix(QRArray [][]uint8) ([][]uint8, Orientation, error) {
+	if err := checkSquareMatrix(QRArray); err != nil {
+		return nil, Orientation{}, err
+	}
+	QRArray = stripUniformBorder(QRArray)
+
+	for _, reversed := range []bool{false, true} {
+		candidate := QRArray
+		if reversed {
+			candidate = invertMatrix(QRArray)
+		}
+		for _, mirrored := range []bool{false, true} {
+			turned := candidate
+			if mirrored {
+				turned = MirrorMatrix(candidate)
+			}
+			for rotation := range 4 {
+				if isNormalSymbol(turned) {
+					return turned, Orientation{Reversed: reversed, Mirrored: mirrored, Rotation: rotation}, nil
+				}
+				turned = rotateMatrix(turned)
+			}
+		}
+	}
+	return nil, Orientation{}, errors.New("no finder patterns with valid format information found")
+}
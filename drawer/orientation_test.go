@@ -0,0 +1,108 @@
+package drawer
+
+import (
+	"QRCodeGenerator/generator"
+	"bytes"
+	"image/color"
+	"io"
+	"slices"
+	"testing"
+)
+
+// testSymbol returns a version 2 symbol with the template of the error level and mask
+// and the data modules of randomMatrix
+func testSymbol() [][]uint8 {
+	QRArray, _ := GetQRTemplate(generator.QRCodeInfo{
+		Version:               2,
+		Size:                  25,
+		ErrorLevel:            generator.ErrorLevel_Q,
+		MaskPatern:            generator.MaskPattern_3,
+		AlignSquareCordenates: generator.QRAlignSquareCordinates[2],
+	})
+	data := randomMatrix(25, 5)
+	for i := range QRArray {
+		for j := range QRArray[i] {
+			if QRArray[i][j] == 0 {
+				QRArray[i][j] = data[i][j]
+			}
+		}
+	}
+	return QRArray
+}
+
+func sameMatrix(a [][]uint8, b [][]uint8) bool {
+	return slices.EqualFunc(a, b, slices.Equal)
+}
+
+func TestNormalizeMatrix(t *testing.T) {
+	symbol := testSymbol()
+	tests := []struct {
+		name        string
+		QRArray     [][]uint8
+		orientation Orientation
+	}{
+		{"normal", symbol, Orientation{}},
+		{"reversed", invertMatrix(symbol), Orientation{Reversed: true}},
+		{"mirrored", MirrorMatrix(symbol), Orientation{Mirrored: true}},
+		{"reversed and mirrored", invertMatrix(MirrorMatrix(symbol)), Orientation{Reversed: true, Mirrored: true}},
+		{"turned", rotateMatrix(symbol), Orientation{Rotation: 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			normalized, orientation, err := NormalizeMatrix(test.QRArray)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if orientation != test.orientation {
+				t.Errorf("got orientation %+v, want %+v", orientation, test.orientation)
+			}
+			if !sameMatrix(normalized, symbol) {
+				t.Error("the matrix is not the normal symbol")
+			}
+		})
+	}
+	if _, _, err := NormalizeMatrix(randomMatrix(25, 6)); err == nil {
+		t.Error("a matrix without finder patterns did not fail")
+	}
+}
+
+func TestMatrixReadersKeepOrientation(t *testing.T) {
+	symbol := testSymbol()
+	for name, QRArray := range map[string][][]uint8{"mirrored": MirrorMatrix(symbol), "reversed": invertMatrix(symbol)} {
+		var text, csv bytes.Buffer
+		if err := WriteMatrixText(&text, QRArray, "#", "."); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteMatrixCSV(&csv, QRArray); err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadMatrixText(&text, "#")
+		if err != nil || !sameMatrix(read, QRArray) {
+			t.Errorf("%s: the text export did not round trip, error %v", name, err)
+		}
+		read, err = ReadMatrixCSV(&csv)
+		if err != nil || !sameMatrix(read, QRArray) {
+			t.Errorf("%s: the CSV export did not round trip, error %v", name, err)
+		}
+	}
+}
+
+func TestReversedColors(t *testing.T) {
+	reversed := ReversedColors(Colors{Dark: color.Black, Light: color.White, Strict: true})
+	if reversed.Dark != color.White || reversed.Light != color.Black {
+		t.Errorf("got dark %v and light %v, want them swapped", reversed.Dark, reversed.Light)
+	}
+	if err := RenderPNG(io.Discard, testSymbol(), PNGOptions{Colors: &reversed}); err != nil {
+		t.Errorf("reversed colors failed: %v", err)
+	}
+
+	inverted := Colors{Dark: color.White, Light: color.Black, Strict: true}
+	if err := RenderPNG(io.Discard, testSymbol(), PNGOptions{Colors: &inverted}); err == nil {
+		t.Error("inverted colors without Reversed did not fail")
+	}
+	lightQuietZone := reversed
+	lightQuietZone.QuietZone = color.White
+	if err := RenderPNG(io.Discard, testSymbol(), PNGOptions{Colors: &lightQuietZone}); err == nil {
+		t.Error("a reversed symbol with a light quiet zone did not fail")
+	}
+}
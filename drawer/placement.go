@@ -0,0 +1,119 @@
+package drawer
+
+import (
+	"QRCodeGenerator/generator"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type PlacementKind string
+
+const (
+	PlacementKind_Data            PlacementKind = "data"
+	PlacementKind_ErrorCorrection PlacementKind = "ec"
+	PlacementKind_Remainder       PlacementKind = "remainder"
+)
+
+// ModulePlacement tells which bit of the final message is written on a module.
+// Remainder bits do not belong to any codeword, their Block, Codeword and Index are -1.
+type ModulePlacement struct {
+	Row      int           `json:"row"`
+	Col      int           `json:"col"`
+	Kind     PlacementKind `json:"kind"`
+	Block    int           `json:"block"`
+	Codeword int           `json:"codeword"` // index inside the block, error correction codewords come after the data ones
+	Index    int           `json:"index"`    // index of the codeword in the interleaved final message
+	Bit      int           `json:"bit"`      // 7 is the most significant bit, for remainder bits the order they are written
+}
+
+// PlacementExport is the JSON representation of the placement map, modules are
+// listed in the order the data is written
+type PlacementExport struct {
+	Version     int               `json:"version"`
+	Size        int               `json:"size"`
+	ErrorLevel  string            `json:"errorLevel"`
+	MaskPattern int               `json:"maskPattern"`
+	Modules     []ModulePlacement `json:"modules"`
+}
+
+func WritePlacementJSON(w io.Writer, placements []ModulePlacement, QRversion generator.QRCodeInfo) error {
+	export := PlacementExport{
+		Version:     QRversion.Version,
+		Size:        QRversion.Size,
+		ErrorLevel:  QRversion.ErrorLevel.Letter(),
+		MaskPattern: int(QRversion.MaskPatern),
+		Modules:     placements,
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+func (placement ModulePlacement) String() string {
+	if placement.Kind == PlacementKind_Remainder {
+		return fmt.Sprintf("row %d, col %d: remainder bit %d", placement.Row, placement.Col, placement.Bit)
+	}
+	kind := "data"
+	if placement.Kind == PlacementKind_ErrorCorrection {
+		kind = "error correction"
+	}
+	return fmt.Sprintf("row %d, col %d: %s, block %d, codeword %d (message codeword %d), bit %d",
+		placement.Row, placement.Col, kind, placement.Block, placement.Codeword, placement.Index, placement.Bit)
+}
+
+// placementFill gives every block its own hue, error correction codewords are less
+// saturated than data ones and dark modules are darker than light ones
+func placementFill(placement ModulePlacement, blocks int, dark bool) string {
+	lightness := 75
+	if dark {
+		lightness = 35
+	}
+	if placement.Kind == PlacementKind_Remainder {
+		return fmt.Sprintf("hsl(0, 0%%, %d%%)", lightness)
+	}
+	saturation := 85
+	if placement.Kind == PlacementKind_ErrorCorrection {
+		saturation = 35
+	}
+	hue := placement.Block * 360 / max(blocks, 1)
+	return fmt.Sprintf("hsl(%d, %d%%, %d%%)", hue, saturation, lightness)
+}
+
+// WritePlacementSVG draws the matrix with every data module colored by its block and
+// kind, hovering a module shows its placement. Function patterns are drawn in gray.
+func WritePlacementSVG(w io.Writer, QRArray [][]uint8, placements []ModulePlacement, moduleSize float64) error {
+	if err := checkSquareMatrix(QRArray); err != nil {
+		return err
+	}
+	if moduleSize == 0 {
+		moduleSize = defaultCellSize
+	}
+	blocks := 0
+	for _, placement := range placements {
+		blocks = max(blocks, placement.Block+1)
+	}
+
+	size := len(QRArray)
+	buffer := bufio.NewWriter(w)
+	fmt.Fprintf(buffer, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%g" height="%g" shape-rendering="crispEdges">`+"\n",
+		size, size, float64(size)*moduleSize, float64(size)*moduleSize)
+	fmt.Fprintf(buffer, `<rect width="%d" height="%d" fill="#e0e0e0"/>`+"\n", size, size)
+	// the function patterns are drawn first and the data modules on top
+	for i := range QRArray {
+		for _, run := range darkRuns(QRArray[i]) {
+			fmt.Fprintf(buffer, `<rect x="%d" y="%d" width="%d" height="1" fill="#606060"/>`+"\n", run[0], i, run[1]-run[0])
+		}
+	}
+	for _, placement := range placements {
+		if placement.Row < 0 || placement.Col < 0 || placement.Row >= size || placement.Col >= size {
+			return fmt.Errorf("placement out of the matrix: %s", placement)
+		}
+		fill := placementFill(placement, blocks, isDark(QRArray[placement.Row][placement.Col]))
+		fmt.Fprintf(buffer, `<rect x="%d" y="%d" width="1" height="1" fill="%s"><title>%s</title></rect>`+"\n",
+			placement.Col, placement.Row, fill, placement)
+	}
+	buffer.WriteString("</svg>\n")
+	return buffer.Flush()
+}
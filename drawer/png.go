@@ -0,0 +1,135 @@
+package drawer
+
+import (
+	"QRCodeGenerator/generator"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"math"
+)
+
+const DEFAULT_DPI = 300
+
+type PNGOptions struct {
+	Scale     int // pixels per module
+	QuietZone int // modules of margin on every side
+	DPI       int // written in the pHYs chunk, 0 leaves the chunk out
+
+	Colors *Colors        // nil uses DefaultColors
+	Style  *Style         // nil draws plain square modules
+	Roles  [][]ModuleRole // role of every module, needed by Style, nil uses GetModuleRoles
+
+	// when set, the generation parameters are written as text chunks, see ReadPNGMetadata
+	Metadata *generator.QRCodeInfo
+}
+
+// pngChunk builds a chunk with its length and CRC
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := make([]byte, 0, len(data)+12)
+	chunk = binary.BigEndian.AppendUint32(chunk, uint32(len(data)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// insertPNGChunks adds the chunks right after the IHDR chunk, before any image data
+func insertPNGChunks(pngData []byte, chunks ...[]byte) ([]byte, error) {
+	// 8 bytes of signature plus the IHDR chunk, which always has 13 bytes of data
+	headerEnd := 8 + 12 + 13
+	if len(pngData) < headerEnd || string(pngData[12:16]) != "IHDR" {
+		return nil, errors.New("invalid PNG data")
+	}
+	result := make([]byte, 0, len(pngData)+len(chunks)*32)
+	result = append(result, pngData[:headerEnd]...)
+	for _, chunk := range chunks {
+		result = append(result, chunk...)
+	}
+	return append(result, pngData[headerEnd:]...), nil
+}
+
+func physChunk(dpi int) []byte {
+	pixelsPerMeter := uint32(math.Round(float64(dpi) / 0.0254))
+	data := make([]byte, 0, 9)
+	data = binary.BigEndian.AppendUint32(data, pixelsPerMeter)
+	data = binary.BigEndian.AppendUint32(data, pixelsPerMeter)
+	data = append(data, 1) // unit is the meter
+	return pngChunk("pHYs", data)
+}
+
+// hasOnlyModuleColors tells if the matrix is finished, without any debug color
+func hasOnlyModuleColors(QRArray [][]uint8) bool {
+	for i := range QRArray {
+		for _, cell := range QRArray[i] {
+			if cell != BLACK_COLOR && cell != WHITE_COLOR {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// encodePNG encodes img with the best compression and adds the extra chunks
+func encodePNG(w io.Writer, img image.Image, chunks ...[]byte) error {
+	var buffer bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buffer, img); err != nil {
+		return err
+	}
+	pngData, err := insertPNGChunks(buffer.Bytes(), chunks...)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(pngData)
+	return err
+}
+
+// RenderPNG writes a 1 bit paletted PNG of the code, styled codes and matrices that
+// still have debug colors (from the intermediate generation steps) are written in full color
+func RenderPNG(w io.Writer, QRArray [][]uint8, options PNGOptions) error {
+	if options.DPI < 0 {
+		return errors.New("DPI can not be negative")
+	}
+	img, err := drawPNGImage(QRArray, &options)
+	if err != nil {
+		return err
+	}
+
+	var chunks [][]byte
+	if options.DPI > 0 {
+		chunks = append(chunks, physChunk(options.DPI))
+	}
+	if options.Metadata != nil {
+		chunks = append(chunks, metadataChunks(*options.Metadata)...)
+	}
+	return encodePNG(w, img, chunks...)
+}
+
+// drawPNGImage returns the image RenderPNG encodes, the defaults are set on options
+func drawPNGImage(QRArray [][]uint8, options *PNGOptions) (image.Image, error) {
+	if options.Scale == 0 {
+		options.Scale = defaultCellSize
+	}
+	if err := checkBitmapParams(options.Scale, options.QuietZone); err != nil {
+		return nil, err
+	}
+
+	colors, err := getColors(options.Colors)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasOnlyModuleColors(QRArray) {
+		return drawQRImage(QRArray, options.Scale, options.QuietZone), nil
+	}
+	if options.Style != nil {
+		return drawStyledImage(QRArray, options.Roles, options.Scale, options.QuietZone, colors, *options.Style)
+	}
+
+	QRImg := NewQRImage(QRArray, options.Scale, options.QuietZone)
+	QRImg.SetColors(colors)
+	return QRImg, nil
+}
@@ -0,0 +1,151 @@
+package drawer
+
+import (
+	"QRCodeGenerator/generator"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"strconv"
+)
+
+const (
+	pngKeyPayload      = "QRCode Payload"
+	pngKeyVersion      = "QRCode Version"
+	pngKeyErrorLevel   = "QRCode Error Level"
+	pngKeyMaskPattern  = "QRCode Mask Pattern"
+	pngKeyEncodingMode = "QRCode Encoding Mode"
+	pngKeySoftware     = "Software"
+)
+
+const pngSoftwareName = "QRCodeGenerator "
+
+// PNGMetadata are the generation parameters stored in a PNG by RenderPNG
+type PNGMetadata struct {
+	Payload          string
+	Version          int
+	ErrorLevel       generator.ErrorLevel
+	MaskPattern      generator.MaskPattern
+	EncodingMode     generator.EncodingMode
+	GeneratorVersion string
+	Text             map[string]string // every text chunk in the file, including the ones above
+}
+
+func textChunk(keyword string, text string) []byte {
+	data := append([]byte(keyword), 0)
+	return pngChunk("tEXt", append(data, text...))
+}
+
+// internationalTextChunk is used for the payload since tEXt only allows Latin-1
+func internationalTextChunk(keyword string, text string) []byte {
+	data := append([]byte(keyword), 0)
+	// no compression, empty language and translated keyword
+	data = append(data, 0, 0, 0, 0)
+	return pngChunk("iTXt", append(data, text...))
+}
+
+func metadataChunks(QRversion generator.QRCodeInfo) [][]byte {
+	return [][]byte{
+		internationalTextChunk(pngKeyPayload, QRversion.InfoToEncode),
+		textChunk(pngKeyVersion, strconv.Itoa(QRversion.Version)),
+		textChunk(pngKeyErrorLevel, QRversion.ErrorLevel.Letter()),
+		textChunk(pngKeyMaskPattern, strconv.Itoa(int(QRversion.MaskPatern))),
+		textChunk(pngKeyEncodingMode, QRversion.EncodingMode.String()),
+		textChunk(pngKeySoftware, pngSoftwareName+generator.GENERATOR_VERSION),
+	}
+}
+
+// readPNGText returns the keyword and text of tEXt and iTXt chunks, compressed iTXt chunks are skipped
+func readPNGText(chunkType string, data []byte) (string, string, bool) {
+	keyword, text, found := bytes.Cut(data, []byte{0})
+	if !found {
+		return "", "", false
+	}
+	if chunkType == "tEXt" {
+		return string(keyword), string(text), true
+	}
+	if len(text) < 2 || text[0] != 0 {
+		return "", "", false
+	}
+	// skip the compression fields, then the language tag and the translated keyword
+	_, text, found = bytes.Cut(text[2:], []byte{0})
+	if !found {
+		return "", "", false
+	}
+	_, text, found = bytes.Cut(text, []byte{0})
+	return string(keyword), string(text), found
+}
+
+// ReadPNGMetadata reads the generation parameters written by RenderPNG
+func ReadPNGMetadata(r io.Reader) (PNGMetadata, error) {
+	metadata := PNGMetadata{Text: map[string]string{}}
+
+	signature := make([]byte, 8)
+	if _, err := io.ReadFull(r, signature); err != nil {
+		return metadata, err
+	}
+	if string(signature) != "\x89PNG\r\n\x1a\n" {
+		return metadata, errors.New("not a PNG file")
+	}
+
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return metadata, err
+		}
+		length := binary.BigEndian.Uint32(header[:4])
+		chunkType := string(header[4:8])
+		if length > 1<<24 {
+			return metadata, errors.New("chunk too big")
+		}
+		data := make([]byte, length+4)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return metadata, err
+		}
+		if crc32.ChecksumIEEE(append(header[4:8:8], data[:length]...)) != binary.BigEndian.Uint32(data[length:]) {
+			return metadata, errors.New("invalid CRC on chunk " + chunkType)
+		}
+
+		if chunkType == "IEND" {
+			break
+		}
+		if chunkType == "tEXt" || chunkType == "iTXt" {
+			if keyword, text, ok := readPNGText(chunkType, data[:length]); ok {
+				metadata.Text[keyword] = text
+			}
+		}
+	}
+
+	if _, found := metadata.Text[pngKeyVersion]; !found {
+		return metadata, errors.New("the PNG has no QR code metadata")
+	}
+	return metadata, metadata.parse()
+}
+
+func (metadata *PNGMetadata) parse() error {
+	var err error
+	metadata.Payload = metadata.Text[pngKeyPayload]
+	if metadata.Version, err = strconv.Atoi(metadata.Text[pngKeyVersion]); err != nil {
+		return err
+	}
+	if metadata.ErrorLevel, err = generator.ParseErrorLevel(metadata.Text[pngKeyErrorLevel]); err != nil {
+		return err
+	}
+	maskPattern, err := strconv.Atoi(metadata.Text[pngKeyMaskPattern])
+	if err != nil {
+		return err
+	}
+	if maskPattern < 0 || maskPattern > int(generator.MaskPattern_7) {
+		return errors.New("invalid mask pattern: " + strconv.Itoa(maskPattern))
+	}
+	metadata.MaskPattern = generator.MaskPattern(maskPattern)
+	if metadata.EncodingMode, err = generator.ParseEncodingMode(metadata.Text[pngKeyEncodingMode]); err != nil {
+		return err
+	}
+	software := metadata.Text[pngKeySoftware]
+	if len(software) > len(pngSoftwareName) && software[:len(pngSoftwareName)] == pngSoftwareName {
+		metadata.GeneratorVersion = software[len(pngSoftwareName):]
+	}
+	return nil
+}
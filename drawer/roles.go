@@ -0,0 +1,27 @@
+package drawer
+
+// ModuleRole tells which part of the symbol a module belongs to, renderers use it
+// to style or protect the function patterns
+type ModuleRole uint8
+
+const (
+	ModuleRole_Data        ModuleRole = iota // data and error correction codewords
+	ModuleRole_FinderOuter                   // dark 7x7 ring of a finder pattern
+	ModuleRole_FinderGap                     // light 5x5 ring of a finder pattern
+	ModuleRole_FinderInner                   // dark 3x3 center of a finder pattern
+	ModuleRole_Separator
+	ModuleRole_Alignment
+	ModuleRole_Timing
+	ModuleRole_Format
+	ModuleRole_Version
+	ModuleRole_DarkModule
+)
+
+func (role ModuleRole) IsFinder() bool {
+	return role == ModuleRole_FinderOuter || role == ModuleRole_FinderGap || role == ModuleRole_FinderInner
+}
+
+// IsFunctionPattern tells if the module is not part of the encoded data
+func (role ModuleRole) IsFunctionPattern() bool {
+	return role != ModuleRole_Data
+}
@@ -0,0 +1,114 @@
+package drawer
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+type Shape uint8
+
+const (
+	Shape_Square Shape = iota
+	Shape_Rounded
+	Shape_Circle
+	Shape_Diamond
+	Shape_Connected // rounded only on the corners that do not touch other dark modules
+)
+
+// roundedRectDistance is the signed distance to a square with a different radius on
+// every corner, in the order top left, top right, bottom right, bottom left
+func roundedRectDistance(center [2]float64, half float64, radii [4]float64, p [2]float64) float64 {
+	var cornerRadius float64
+	switch {
+	case p[0] < center[0] && p[1] < center[1]:
+		cornerRadius = radii[0]
+	case p[1] < center[1]:
+		cornerRadius = radii[1]
+	case p[0] >= center[0]:
+		cornerRadius = radii[2]
+	default:
+		cornerRadius = radii[3]
+	}
+	cornerRadius = math.Min(math.Max(cornerRadius, 0), half)
+
+	qx := math.Abs(p[0]-center[0]) - (half - cornerRadius)
+	qy := math.Abs(p[1]-center[1]) - (half - cornerRadius)
+	outside := math.Hypot(math.Max(qx, 0), math.Max(qy, 0))
+	inside := math.Min(math.Max(qx, qy), 0)
+	return outside + inside - cornerRadius
+}
+
+// shapeDistance returns the signed distance in pixels from p to the border of the shape
+// centered on center with the given half side, negative values are inside.
+// cornerRadius is only used by Shape_Rounded, Shape_Connected is drawn as a square here.
+func shapeDistance(shape Shape, center [2]float64, half float64, cornerRadius float64, p [2]float64) float64 {
+	dx := math.Abs(p[0] - center[0])
+	dy := math.Abs(p[1] - center[1])
+
+	switch shape {
+	case Shape_Circle:
+		return math.Hypot(dx, dy) - half
+	case Shape_Rounded:
+		return roundedRectDistance(center, half, [4]float64{cornerRadius, cornerRadius, cornerRadius, cornerRadius}, p)
+	case Shape_Diamond:
+		return (dx + dy - half) / math.Sqrt2
+	}
+	return math.Max(dx, dy) - half
+}
+
+// connectedRadii rounds the corners of the module (i, j) whose two neighbours on that
+// corner are light, so runs of dark modules look like a single rounded shape
+func connectedRadii(QRArray [][]uint8, i int, j int, radius float64) [4]float64 {
+	dark := func(i int, j int) bool {
+		return i >= 0 && j >= 0 && i < len(QRArray) && j < len(QRArray) && isDark(QRArray[i][j])
+	}
+	up, right, down, left := dark(i-1, j), dark(i, j+1), dark(i+1, j), dark(i, j-1)
+	var radii [4]float64
+	for corner, neighbours := range [4][2]bool{{up, left}, {up, right}, {down, right}, {down, left}} {
+		if !neighbours[0] && !neighbours[1] {
+			radii[corner] = radius
+		}
+	}
+	return radii
+}
+
+// blendPixel draws c over the pixel with the given coverage, from 0 to 1
+func blendPixel(img *image.RGBA, x int, y int, c color.Color, coverage float64) {
+	if coverage <= 0 || !(image.Point{x, y}.In(img.Bounds())) {
+		return
+	}
+	coverage = math.Min(coverage, 1)
+	r, g, b, a := c.RGBA()
+	alpha := float64(a) * coverage / 0xffff
+	current := img.RGBAAt(x, y)
+	mix := func(src uint32, dst uint8) uint8 {
+		// src is already premultiplied by its alpha
+		return uint8((float64(src)*coverage/0xffff)*255 + float64(dst)*(1-alpha) + 0.5)
+	}
+	img.SetRGBA(x, y, color.RGBA{mix(r, current.R), mix(g, current.G), mix(b, current.B), mix(a, current.A)})
+}
+
+// fillShape draws an antialiased shape inside the square of side pixels with its top left corner at (x, y)
+func fillShape(img *image.RGBA, shape Shape, x float64, y float64, side float64, cornerRadius float64, c color.Color) {
+	half := side / 2
+	center := [2]float64{x + half, y + half}
+	for py := int(math.Floor(y)); py < int(math.Ceil(y+side)); py++ {
+		for px := int(math.Floor(x)); px < int(math.Ceil(x+side)); px++ {
+			distance := shapeDistance(shape, center, half, cornerRadius, [2]float64{float64(px) + 0.5, float64(py) + 0.5})
+			blendPixel(img, px, py, c, 0.5-distance)
+		}
+	}
+}
+
+// fillRoundedRect draws an antialiased square with a different radius on every corner
+func fillRoundedRect(img *image.RGBA, x float64, y float64, side float64, radii [4]float64, c color.Color) {
+	half := side / 2
+	center := [2]float64{x + half, y + half}
+	for py := int(math.Floor(y)); py < int(math.Ceil(y+side)); py++ {
+		for px := int(math.Floor(x)); px < int(math.Ceil(x+side)); px++ {
+			distance := roundedRectDistance(center, half, radii, [2]float64{float64(px) + 0.5, float64(py) + 0.5})
+			blendPixel(img, px, py, c, 0.5-distance)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package drawer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+type STLOptions struct {
+	ModuleSize    float64 // in mm
+	BaseThickness float64 // height of the plate in mm
+	ReliefHeight  float64 // height of the dark modules over the plate in mm
+	QuietZone     int     // modules of plate around the code
+	Binary        bool
+}
+
+type stlVertex [3]float64
+
+type stlTriangle struct {
+	normal   stlVertex
+	vertices [3]stlVertex
+}
+
+// STL_CORNER_INSET is the side of the square cut out of a dark module corner that only
+// touches another dark module, as a fraction of the module size
+const STL_CORNER_INSET = 0.05
+
+// stlMesh builds the triangles for a plate with the dark modules raised over it.
+// Every module is split in 3x3 cells, the corner cells are STL_CORNER_INSET wide.
+// Cells with the same height are merged into rectangles and walls along a line are
+// merged into runs. Faces are only cut at the corners of those rectangles, every face
+// that touches one on its border gets it as a vertex, so faces meet at shared vertices.
+type stlMesh struct {
+	QRArray   [][]uint8
+	options   STLOptions
+	size      int // modules per side including the quiet zone
+	cells     int // cells per side
+	corners   map[[2]int]bool
+	triangles []stlTriangle
+}
+
+// stlRectangle covers the cells from (x0, y0) to (x1, y1), x1 and y1 excluded
+type stlRectangle struct {
+	x0, y0, x1, y1 int
+}
+
+// moduleRaised tells if the module at column x and row y, counted from the bottom, is dark
+func (mesh *stlMesh) moduleRaised(x int, y int) bool {
+	i := mesh.size - 1 - y - mesh.options.QuietZone
+	j := x - mesh.options.QuietZone
+	if i < 0 || j < 0 || i >= len(mesh.QRArray) || j >= len(mesh.QRArray) {
+		return false
+	}
+	return isDark(mesh.QRArray[i][j])
+}
+
+// raised tells if the cell at column x and row y, counted from the bottom, is raised.
+// Two dark modules that only touch by a corner would share a vertical edge between four
+// walls, so the corner cells of both are left low and the mesh stays manifold.
+func (mesh *stlMesh) raised(x int, y int) bool {
+	if x < 0 || y < 0 || x >= mesh.cells || y >= mesh.cells {
+		return false
+	}
+	moduleX, moduleY := x/3, y/3
+	if !mesh.moduleRaised(moduleX, moduleY) {
+		return false
+	}
+	if x%3 == 1 || y%3 == 1 {
+		return true
+	}
+	// -1 or 1, the side of the corner
+	dx, dy := x%3-1, y%3-1
+	return !mesh.moduleRaised(moduleX+dx, moduleY+dy) || mesh.moduleRaised(moduleX+dx, moduleY) || mesh.moduleRaised(moduleX, moduleY+dy)
+}
+
+func (mesh *stlMesh) height(x int, y int) float64 {
+	if mesh.raised(x, y) {
+		return mesh.options.BaseThickness + mesh.options.ReliefHeight
+	}
+	return mesh.options.BaseThickness
+}
+
+// cellBorder is the position in modules of the cell border number k
+func cellBorder(k int) float64 {
+	return float64(k/3) + [3]float64{0, STL_CORNER_INSET, 1 - STL_CORNER_INSET}[k%3]
+}
+
+func (mesh *stlMesh) vertex(x int, y int, z float64) stlVertex {
+	return stlVertex{cellBorder(x) * mesh.options.ModuleSize, cellBorder(y) * mesh.options.ModuleSize, z}
+}
+
+func (mesh *stlMesh) addTriangle(normal stlVertex, a stlVertex, b stlVertex, c stlVertex) {
+	mesh.triangles = append(mesh.triangles, stlTriangle{normal: normal, vertices: [3]stlVertex{a, b, c}})
+}
+
+// addPolygon adds a flat rectangle whose border points go counter clockwise seen from
+// outside, points in the middle of a side are kept so the faces next to it can share them
+func (mesh *stlMesh) addPolygon(normal stlVertex, points []stlVertex) {
+	if len(points) == 4 {
+		mesh.addTriangle(normal, points[0], points[1], points[2])
+		mesh.addTriangle(normal, points[0], points[2], points[3])
+		return
+	}
+	low, high := points[0], points[0]
+	for _, point := range points {
+		for k := range 3 {
+			low[k] = min(low[k], point[k])
+			high[k] = max(high[k], point[k])
+		}
+	}
+	center := stlVertex{(low[0] + high[0]) / 2, (low[1] + high[1]) / 2, (low[2] + high[2]) / 2}
+	for k := range points {
+		mesh.addTriangle(normal, center, points[k], points[(k+1)%len(points)])
+	}
+}
+
+// cornersOnLine returns the rectangle corners from (x0, y0) to (x1, y1) on a horizontal or
+// vertical line, both ends included, in the direction of the line
+func (mesh *stlMesh) cornersOnLine(x0 int, y0 int, x1 int, y1 int) [][2]int {
+	dx, dy := sign(x1-x0), sign(y1-y0)
+	points := [][2]int{{x0, y0}}
+	for x, y := x0+dx, y0+dy; x != x1 || y != y1; x, y = x+dx, y+dy {
+		if mesh.corners[[2]int{x, y}] {
+			points = append(points, [2]int{x, y})
+		}
+	}
+	return append(points, [2]int{x1, y1})
+}
+
+func sign(value int) int {
+	switch {
+	case value > 0:
+		return 1
+	case value < 0:
+		return -1
+	}
+	return 0
+}
+
+// getRectangles merges the cells with the same height into rectangles
+func (mesh *stlMesh) getRectangles() []stlRectangle {
+	var rectangles []stlRectangle
+	used := make([][]bool, mesh.cells)
+	for y := range used {
+		used[y] = make([]bool, mesh.cells)
+	}
+	for y := range mesh.cells {
+		for x := range mesh.cells {
+			if used[y][x] {
+				continue
+			}
+			raised := mesh.raised(x, y)
+			x1 := x
+			for x1 < mesh.cells && !used[y][x1] && mesh.raised(x1, y) == raised {
+				x1++
+			}
+			y1 := y + 1
+			for y1 < mesh.cells {
+				sameRow := true
+				for k := x; k < x1; k++ {
+					if used[y1][k] || mesh.raised(k, y1) != raised {
+						sameRow = false
+						break
+					}
+				}
+				if !sameRow {
+					break
+				}
+				y1++
+			}
+			for i := y; i < y1; i++ {
+				for j := x; j < x1; j++ {
+					used[i][j] = true
+				}
+			}
+			rectangles = append(rectangles, stlRectangle{x, y, x1, y1})
+		}
+	}
+	return rectangles
+}
+
+// addTop adds the tops of the rectangles
+func (mesh *stlMesh) addTop(rectangles []stlRectangle) {
+	for _, rectangle := range rectangles {
+		z := mesh.height(rectangle.x0, rectangle.y0)
+		var border [][2]int
+		sides := [][4]int{
+			{rectangle.x0, rectangle.y0, rectangle.x1, rectangle.y0},
+			{rectangle.x1, rectangle.y0, rectangle.x1, rectangle.y1},
+			{rectangle.x1, rectangle.y1, rectangle.x0, rectangle.y1},
+			{rectangle.x0, rectangle.y1, rectangle.x0, rectangle.y0},
+		}
+		for _, side := range sides {
+			points := mesh.cornersOnLine(side[0], side[1], side[2], side[3])
+			// the last point is the first one of the next side
+			border = append(border, points[:len(points)-1]...)
+		}
+		points := make([]stlVertex, len(border))
+		for k, point := range border {
+			points[k] = mesh.vertex(point[0], point[1], z)
+		}
+		mesh.addPolygon(stlVertex{0, 0, 1}, points)
+	}
+}
+
+// addWall adds a vertical wall from (x0, y0) to (x1, y1) between the heights bottom and
+// top, its outside is on the right going from the first point to the second. Only the top
+// side is cut at the rectangle corners when cutBottom is false.
+func (mesh *stlMesh) addWall(x0 int, y0 int, x1 int, y1 int, bottom float64, top float64, cutBottom bool) {
+	normal := stlVertex{float64(sign(y1 - y0)), float64(-sign(x1 - x0)), 0}
+	var points []stlVertex
+	line := mesh.cornersOnLine(x0, y0, x1, y1)
+	if !cutBottom {
+		line = [][2]int{line[0], line[len(line)-1]}
+	}
+	for _, point := range line {
+		points = append(points, mesh.vertex(point[0], point[1], bottom))
+	}
+	line = mesh.cornersOnLine(x1, y1, x0, y0)
+	for _, point := range line {
+		points = append(points, mesh.vertex(point[0], point[1], top))
+	}
+	mesh.addPolygon(normal, points)
+}
+
+// addWalls adds the sides of the plate and the sides of the raised modules, every run of
+// cells with the same wall along a line is a single wall
+func (mesh *stlMesh) addWalls() {
+	base := mesh.options.BaseThickness
+	top := base + mesh.options.ReliefHeight
+	last := mesh.cells
+
+	// outer walls, one per side
+	mesh.addWall(0, 0, last, 0, 0, base, false)
+	mesh.addWall(last, 0, last, last, 0, base, false)
+	mesh.addWall(last, last, 0, last, 0, base, false)
+	mesh.addWall(0, last, 0, 0, 0, base, false)
+
+	// wall kinds: 0 no wall, 1 the raised cell is on the left or below, -1 on the other side
+	wallKind := func(before bool, after bool) int {
+		if before == after {
+			return 0
+		}
+		if before {
+			return 1
+		}
+		return -1
+	}
+	for line := 0; line <= mesh.cells; line++ {
+		for _, vertical := range []bool{true, false} {
+			kind := func(k int) int {
+				if vertical {
+					return wallKind(mesh.raised(line-1, k), mesh.raised(line, k))
+				}
+				return wallKind(mesh.raised(k, line-1), mesh.raised(k, line))
+			}
+			for start := 0; start < mesh.cells; {
+				wall := kind(start)
+				end := start + 1
+				for end < mesh.cells && kind(end) == wall {
+					end++
+				}
+				switch {
+				case wall == 0:
+				case vertical && wall == 1:
+					mesh.addWall(line, start, line, end, base, top, true)
+				case vertical:
+					mesh.addWall(line, end, line, start, base, top, true)
+				case wall == 1:
+					mesh.addWall(end, line, start, line, base, top, true)
+				default:
+					mesh.addWall(start, line, end, line, base, top, true)
+				}
+				start = end
+			}
+		}
+	}
+}
+
+// WriteSTL writes a printable plate with the dark modules raised over it. The mesh is
+// closed and manifold, dark modules that only touch by a corner have that corner cut
+// by STL_CORNER_INSET so they do not share an edge.
+func WriteSTL(w io.Writer, QRArray [][]uint8, options STLOptions) error {
+	if options.ModuleSize == 0 {
+		options.ModuleSize = 2
+	}
+	if options.BaseThickness == 0 {
+		options.BaseThickness = 2
+	}
+	if options.ReliefHeight == 0 {
+		options.ReliefHeight = 1
+	}
+	if options.ModuleSize < 0 || options.BaseThickness < 0 || options.ReliefHeight < 0 || options.QuietZone < 0 {
+		return errors.New("STL options can not be negative")
+	}
+	if err := checkSquareMatrix(QRArray); err != nil {
+		return err
+	}
+
+	size := len(QRArray) + 2*options.QuietZone
+	mesh := stlMesh{QRArray: QRArray, options: options, size: size, cells: 3 * size, corners: make(map[[2]int]bool)}
+	rectangles := mesh.getRectangles()
+	for _, rectangle := range rectangles {
+		for _, corner := range [4][2]int{{rectangle.x0, rectangle.y0}, {rectangle.x1, rectangle.y0}, {rectangle.x1, rectangle.y1}, {rectangle.x0, rectangle.y1}} {
+			mesh.corners[corner] = true
+		}
+	}
+	// the bottom only meets the outer walls, which are only cut at the plate corners
+	mesh.addPolygon(stlVertex{0, 0, -1}, []stlVertex{mesh.vertex(0, 0, 0), mesh.vertex(0, mesh.cells, 0), mesh.vertex(mesh.cells, mesh.cells, 0), mesh.vertex(mesh.cells, 0, 0)})
+	mesh.addTop(rectangles)
+	mesh.addWalls()
+
+	buffer := bufio.NewWriter(w)
+	if options.Binary {
+		writeSTLBinary(buffer, mesh.triangles)
+	} else {
+		writeSTLASCII(buffer, mesh.triangles)
+	}
+	return buffer.Flush()
+}
+
+func writeSTLASCII(buffer *bufio.Writer, triangles []stlTriangle) {
+	buffer.WriteString("solid qrcode\n")
+	for _, triangle := range triangles {
+		fmt.Fprintf(buffer, "  facet normal %g %g %g\n    outer loop\n", triangle.normal[0], triangle.normal[1], triangle.normal[2])
+		for _, vertex := range triangle.vertices {
+			fmt.Fprintf(buffer, "      vertex %g %g %g\n", vertex[0], vertex[1], vertex[2])
+		}
+		buffer.WriteString("    endloop\n  endfacet\n")
+	}
+	buffer.WriteString("endsolid qrcode\n")
+}
+
+func writeSTLBinary(buffer *bufio.Writer, triangles []stlTriangle) {
+	header := make([]byte, 80)
+	copy(header, "QRCodeGenerator binary STL")
+	buffer.Write(header)
+	binary.Write(buffer, binary.LittleEndian, uint32(len(triangles)))
+
+	record := make([]byte, 50)
+	for _, triangle := range triangles {
+		values := append([]stlVertex{triangle.normal}, triangle.vertices[:]...)
+		for i, vertex := range values {
+			for k := range 3 {
+				binary.LittleEndian.PutUint32(record[(i*3+k)*4:], math.Float32bits(float32(vertex[k])))
+			}
+		}
+		// attribute byte count, unused
+		record[48], record[49] = 0, 0
+		buffer.Write(record)
+	}
+}
@@ -0,0 +1,136 @@
+package drawer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randomMatrix returns a matrix with half of the modules dark, always the same for a seed
+func randomMatrix(size int, seed int64) [][]uint8 {
+	random := rand.New(rand.NewSource(seed))
+	QRArray := make([][]uint8, size)
+	for i := range QRArray {
+		QRArray[i] = make([]uint8, size)
+		for j := range QRArray[i] {
+			QRArray[i][j] = WHITE_COLOR
+			if random.Intn(2) == 0 {
+				QRArray[i][j] = BLACK_COLOR
+			}
+		}
+	}
+	return QRArray
+}
+
+type stlEdge [2][3]float32
+
+// readSTLBinary returns the vertices of every triangle of a binary STL
+func readSTLBinary(t *testing.T, data []byte) [][3][3]float32 {
+	t.Helper()
+	if len(data) < 84 {
+		t.Fatalf("STL too short: %d bytes", len(data))
+	}
+	count := int(binary.LittleEndian.Uint32(data[80:84]))
+	if len(data) != 84+count*50 {
+		t.Fatalf("STL has %d bytes for %d triangles", len(data), count)
+	}
+	triangles := make([][3][3]float32, count)
+	for triangle := range count {
+		record := data[84+triangle*50:]
+		for v := range 3 {
+			for k := range 3 {
+				triangles[triangle][v][k] = math.Float32frombits(binary.LittleEndian.Uint32(record[(v*3+k+3)*4:]))
+			}
+		}
+	}
+	return triangles
+}
+
+// stlVolume is the signed volume of a closed mesh, positive when the triangles face out
+func stlVolume(triangles [][3][3]float32) float64 {
+	volume := 0.0
+	for _, triangle := range triangles {
+		a, b, c := triangle[0], triangle[1], triangle[2]
+		volume += float64(a[0])*(float64(b[1])*float64(c[2])-float64(b[2])*float64(c[1])) -
+			float64(a[1])*(float64(b[0])*float64(c[2])-float64(b[2])*float64(c[0])) +
+			float64(a[2])*(float64(b[0])*float64(c[1])-float64(b[1])*float64(c[0]))
+	}
+	return volume / 6
+}
+
+func TestWriteSTLIsManifold(t *testing.T) {
+	const D, L = BLACK_COLOR, WHITE_COLOR
+	tests := []struct {
+		name      string
+		QRArray   [][]uint8
+		quietZone int
+	}{
+		{"diagonal", [][]uint8{{D, L}, {L, D}}, 1},
+		{"anti diagonal", [][]uint8{{L, D}, {D, L}}, 1},
+		{"diagonal on the plate border", [][]uint8{{D, L}, {L, D}}, 0},
+		{"checkerboard", [][]uint8{{D, L, D}, {L, D, L}, {D, L, D}}, 2},
+		{"diagonal next to a block", [][]uint8{{D, D, L}, {D, D, L}, {L, L, D}}, 1},
+		{"ring", [][]uint8{{D, D, D}, {D, L, D}, {D, D, D}}, 1},
+		{"all dark", [][]uint8{{D, D}, {D, D}}, 0},
+		{"random", randomMatrix(25, 1), 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := STLOptions{ModuleSize: 2, BaseThickness: 2, ReliefHeight: 1, QuietZone: test.quietZone, Binary: true}
+			var buffer bytes.Buffer
+			if err := WriteSTL(&buffer, test.QRArray, options); err != nil {
+				t.Fatal(err)
+			}
+			triangles := readSTLBinary(t, buffer.Bytes())
+
+			// every edge is used once in each direction, by exactly two triangles with the same winding
+			edges := make(map[stlEdge]int)
+			for _, triangle := range triangles {
+				for v := range 3 {
+					edges[stlEdge{triangle[v], triangle[(v+1)%3]}]++
+				}
+			}
+			for edge, uses := range edges {
+				if uses != 1 {
+					t.Fatalf("edge %v is used %d times in the same direction", edge, uses)
+				}
+				if edges[stlEdge{edge[1], edge[0]}] != 1 {
+					t.Fatalf("edge %v has no opposite edge", edge)
+				}
+			}
+
+			// the plate and the dark modules, less the corners cut from them
+			side := float64(len(test.QRArray)+2*test.quietZone) * options.ModuleSize
+			darkModules := 0
+			for i := range test.QRArray {
+				for j := range test.QRArray[i] {
+					if isDark(test.QRArray[i][j]) {
+						darkModules++
+					}
+				}
+			}
+			moduleVolume := options.ModuleSize * options.ModuleSize * options.ReliefHeight
+			most := side*side*options.BaseThickness + float64(darkModules)*moduleVolume
+			least := most - float64(darkModules)*4*STL_CORNER_INSET*STL_CORNER_INSET*moduleVolume
+			if volume := stlVolume(triangles); volume < least-1e-3 || volume > most+1e-3 {
+				t.Fatalf("the mesh has a volume of %g, want between %g and %g", volume, least, most)
+			}
+		})
+	}
+}
+
+// TestWriteSTLTriangleCount bounds the mesh of a random matrix the size of version 2, the
+// faces of the cells are merged so an isolated module (2 triangles on top and 8 on the
+// walls) costs more than a module in the middle of others
+func TestWriteSTLTriangleCount(t *testing.T) {
+	QRArray := randomMatrix(25, 1)
+	var buffer bytes.Buffer
+	if err := WriteSTL(&buffer, QRArray, STLOptions{QuietZone: 4, Binary: true}); err != nil {
+		t.Fatal(err)
+	}
+	if triangles, most := len(readSTLBinary(t, buffer.Bytes())), 8*25*25; triangles > most {
+		t.Errorf("got %d triangles, want at most %d", triangles, most)
+	}
+}
@@ -0,0 +1,190 @@
+package drawer
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// FinderStyle styles the three finder patterns ("eyes") apart from the data modules
+type FinderStyle struct {
+	OuterColor  color.Color // nil uses the dark color
+	OuterShape  Shape
+	OuterRadius float64 // corner radius in modules, only for Shape_Rounded
+	InnerColor  color.Color
+	InnerShape  Shape
+	InnerRadius float64
+}
+
+type Style struct {
+	Finder FinderStyle
+
+	// shape of the dark data modules, the other function patterns are always drawn as
+	// squares so the code stays scannable
+	ModuleShape  Shape
+	ModuleSize   float64 // fraction of the cell covered by each module, 0 covers the whole cell
+	ModuleRadius float64 // corner radius as a fraction of the module, for Shape_Rounded and Shape_Connected
+
+	// colors of the dark modules, the finder patterns keep their plain colors.
+	// Every module is darkened until it has FillContrast against the light color,
+	// 0 uses MIN_CONTRAST_RATIO.
+	Fill         Fill
+	FillContrast float64
+
+	Logo *Logo // nil draws no logo
+}
+
+// moduleGeometry returns the offset inside the cell and the side of a data module, in
+// cell units, plus its corner radius as a fraction of the module
+func (style Style) moduleGeometry() (float64, float64, float64) {
+	moduleSize := style.ModuleSize
+	if moduleSize <= 0 || moduleSize > 1 || style.ModuleShape == Shape_Connected {
+		// connected modules must cover the whole cell to touch their neighbours
+		moduleSize = 1
+	}
+	radius := style.ModuleRadius
+	if radius <= 0 && style.ModuleShape == Shape_Connected {
+		radius = 0.5
+	} else if radius <= 0 {
+		radius = 0.25
+	}
+	return (1 - moduleSize) / 2, moduleSize, math.Min(radius, 0.5)
+}
+
+// getRoles returns the roles to draw QRArray with, the ones GetModuleRoles gives for
+// its size when roles is nil
+func getRoles(QRArray [][]uint8, roles [][]ModuleRole) ([][]ModuleRole, error) {
+	if roles == nil {
+		return GetModuleRoles(len(QRArray))
+	}
+	if len(roles) != len(QRArray) {
+		return nil, errors.New("module roles do not match the matrix")
+	}
+	for i := range roles {
+		if len(roles[i]) != len(QRArray[i]) {
+			return nil, errors.New("module roles do not match the matrix")
+		}
+	}
+	return roles, nil
+}
+
+// fillShapeWithHole works like fillShape but leaves the pixels inside a hole of the same
+// shape, inset pixels smaller on every side, untouched. Drawing the hole with the light
+// color instead would not work with transparent backgrounds.
+func fillShapeWithHole(img *image.RGBA, shape Shape, x float64, y float64, side float64, cornerRadius float64, inset float64, c color.Color) {
+	half := side / 2
+	center := [2]float64{x + half, y + half}
+	for py := int(math.Floor(y)); py < int(math.Ceil(y+side)); py++ {
+		for px := int(math.Floor(x)); px < int(math.Ceil(x+side)); px++ {
+			p := [2]float64{float64(px) + 0.5, float64(py) + 0.5}
+			coverage := 0.5 - shapeDistance(shape, center, half, cornerRadius, p)
+			holeDistance := shapeDistance(shape, center, half-inset, math.Max(cornerRadius-inset, 0), p)
+			coverage = math.Min(coverage, 1) * math.Min(math.Max(0.5+holeDistance, 0), 1)
+			blendPixel(img, px, py, c, coverage)
+		}
+	}
+}
+
+// finderCorners returns the top left module of every finder pattern in the roles
+func finderCorners(roles [][]ModuleRole) []image.Point {
+	var corners []image.Point
+	for i := range roles {
+		for j := range roles[i] {
+			if roles[i][j] != ModuleRole_FinderOuter {
+				continue
+			}
+			if (i == 0 || !roles[i-1][j].IsFinder()) && (j == 0 || !roles[i][j-1].IsFinder()) {
+				corners = append(corners, image.Point{j, i})
+			}
+		}
+	}
+	return corners
+}
+
+// drawStyledImage draws the code in full color with the finder patterns styled apart
+func drawStyledImage(QRArray [][]uint8, roles [][]ModuleRole, scale int, quietZone int, colors Colors, style Style) (*image.RGBA, error) {
+	roles, err := getRoles(QRArray, roles)
+	if err != nil {
+		return nil, err
+	}
+	if err := style.Logo.check(len(QRArray)); err != nil {
+		return nil, err
+	}
+
+	finder := style.Finder
+	if finder.OuterColor == nil {
+		finder.OuterColor = colors.Dark
+	}
+	if finder.InnerColor == nil {
+		finder.InnerColor = colors.Dark
+	}
+	for _, finderColor := range []color.Color{finder.OuterColor, finder.InnerColor} {
+		if err := CheckContrast(Colors{Dark: finderColor, Light: colors.Light, QuietZone: colors.QuietZone, Strict: colors.Strict, Reversed: colors.Reversed}); err != nil {
+			return nil, err
+		}
+	}
+
+	size := bitmapSize(QRArray, scale, quietZone)
+	QRImage := image.NewRGBA(image.Rect(0, 0, size, size))
+	quietZoneColor := colors.QuietZone
+	if quietZoneColor == nil {
+		quietZoneColor = colors.Light
+	}
+	draw.Draw(QRImage, QRImage.Bounds(), &image.Uniform{quietZoneColor}, image.Point{}, draw.Src)
+	codeArea := image.Rect(quietZone*scale, quietZone*scale, size-quietZone*scale, size-quietZone*scale)
+	draw.Draw(QRImage, codeArea, &image.Uniform{colors.Light}, image.Point{}, draw.Src)
+
+	cellPosition := func(index int) float64 {
+		return float64((index + quietZone) * scale)
+	}
+
+	moduleSize := float64(scale)
+	offset, side, radius := style.moduleGeometry()
+	drawModule := func(i int, j int) {
+		moduleColor := style.moduleColor(i, j, len(QRArray), colors)
+		if roles[i][j].IsFunctionPattern() {
+			fillShape(QRImage, Shape_Square, cellPosition(j), cellPosition(i), moduleSize, 0, moduleColor)
+			return
+		}
+
+		x := cellPosition(j) + offset*moduleSize
+		y := cellPosition(i) + offset*moduleSize
+		switch style.ModuleShape {
+		case Shape_Connected:
+			fillRoundedRect(QRImage, x, y, side*moduleSize, connectedRadii(QRArray, i, j, radius*moduleSize), moduleColor)
+		default:
+			fillShape(QRImage, style.ModuleShape, x, y, side*moduleSize, radius*side*moduleSize, moduleColor)
+		}
+	}
+	for i := range QRArray {
+		for j, cell := range QRArray[i] {
+			if roles[i][j].IsFinder() || !isDark(cell) || style.Logo.covers(len(QRArray), i, j) {
+				continue
+			}
+			drawModule(i, j)
+		}
+	}
+
+	if style.Logo != nil {
+		drawLogo(QRImage, len(QRArray), scale, quietZone, colors, style.Logo)
+		// function patterns under the logo are needed to read the code, they go on top
+		area := style.Logo.logoArea(len(QRArray))
+		for i := area.Min.Y; i < area.Max.Y; i++ {
+			for j := area.Min.X; j < area.Max.X; j++ {
+				if roles[i][j].IsFunctionPattern() && !roles[i][j].IsFinder() && isDark(QRArray[i][j]) {
+					drawModule(i, j)
+				}
+			}
+		}
+	}
+
+	for _, corner := range finderCorners(roles) {
+		x := cellPosition(corner.X)
+		y := cellPosition(corner.Y)
+		fillShapeWithHole(QRImage, finder.OuterShape, x, y, 7*moduleSize, finder.OuterRadius*moduleSize, moduleSize, finder.OuterColor)
+		fillShape(QRImage, finder.InnerShape, x+2*moduleSize, y+2*moduleSize, 3*moduleSize, finder.InnerRadius*moduleSize, finder.InnerColor)
+	}
+	return QRImage, nil
+}
@@ -0,0 +1,35 @@
+package drawer
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+)
+
+func TestStyleWithoutRoles(t *testing.T) {
+	QRArray := randomMatrix(25, 3)
+	roles, err := GetModuleRoles(len(QRArray))
+	if err != nil {
+		t.Fatal(err)
+	}
+	style := &Style{Finder: FinderStyle{OuterColor: color.RGBA{0, 0, 128, 255}}}
+
+	var withRoles, withoutRoles bytes.Buffer
+	if err := RenderPNG(&withRoles, QRArray, PNGOptions{Style: style, Roles: roles}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RenderPNG(&withoutRoles, QRArray, PNGOptions{Style: style}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(withRoles.Bytes(), withoutRoles.Bytes()) {
+		t.Error("the PNG without roles does not use the roles of its size")
+	}
+
+	// no version has 24 modules
+	if err := RenderPNG(&withoutRoles, randomMatrix(24, 3), PNGOptions{Style: style}); err == nil {
+		t.Error("a matrix without a version did not fail")
+	}
+	if err := WriteSVG(&withoutRoles, randomMatrix(24, 3), SVGOptions{Style: style}); err == nil {
+		t.Error("SVG: a matrix without a version did not fail")
+	}
+}
@@ -0,0 +1,213 @@
+package drawer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+)
+
+type SVGOptions struct {
+	ModuleSize float64        // size of a module in px, the drawing itself uses one unit per module
+	QuietZone  int            // modules of margin on every side
+	Colors     *Colors        // nil uses DefaultColors
+	Style      *Style         // nil draws plain square modules
+	Roles      [][]ModuleRole // role of every module, needed by Style, nil uses GetModuleRoles
+}
+
+// svgColor returns the color in hex and its opacity
+func svgColor(c color.Color) (string, float64) {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", nrgba.R, nrgba.G, nrgba.B), float64(nrgba.A) / 255
+}
+
+func svgFill(c color.Color) string {
+	hex, opacity := svgColor(c)
+	if opacity >= 1 {
+		return fmt.Sprintf(`fill="%s"`, hex)
+	}
+	return fmt.Sprintf(`fill="%s" fill-opacity="%.3g"`, hex, opacity)
+}
+
+func svgSquarePath(x float64, y float64, side float64) string {
+	return fmt.Sprintf("M%g %gh%gv%gh%gz", x, y, side, side, -side)
+}
+
+// svgRoundedRectPath uses the same corner order as roundedRectDistance
+func svgRoundedRectPath(x float64, y float64, side float64, radii [4]float64) string {
+	for i := range radii {
+		radii[i] = math.Min(math.Max(radii[i], 0), side/2)
+	}
+	return fmt.Sprintf("M%g %gH%gA%g %g 0 0 1 %g %gV%gA%g %g 0 0 1 %g %gH%gA%g %g 0 0 1 %g %gV%gA%g %g 0 0 1 %g %gz",
+		x+radii[0], y,
+		x+side-radii[1], radii[1], radii[1], x+side, y+radii[1],
+		y+side-radii[2], radii[2], radii[2], x+side-radii[2], y+side,
+		x+radii[3], radii[3], radii[3], x, y+side-radii[3],
+		y+radii[0], radii[0], radii[0], x+radii[0], y)
+}
+
+// svgShapePath returns the path of the shape inside the square of the given side at (x, y)
+func svgShapePath(shape Shape, x float64, y float64, side float64, cornerRadius float64) string {
+	half := side / 2
+	switch shape {
+	case Shape_Circle:
+		return fmt.Sprintf("M%g %ga%g %g 0 1 0 %g 0a%g %g 0 1 0 %g 0z", x, y+half, half, half, side, half, half, -side)
+	case Shape_Rounded:
+		return svgRoundedRectPath(x, y, side, [4]float64{cornerRadius, cornerRadius, cornerRadius, cornerRadius})
+	case Shape_Diamond:
+		return fmt.Sprintf("M%g %gl%g %gl%g %gl%g %gz", x+half, y, half, half, -half, half, -half, -half)
+	}
+	return svgSquarePath(x, y, side)
+}
+
+// WriteSVG writes the code as an SVG, plain codes use a single path with the
+// dark runs merged, styled codes follow the same rules as the PNG renderer
+func WriteSVG(w io.Writer, QRArray [][]uint8, options SVGOptions) error {
+	colors, err := checkSVGOptions(&options)
+	if err != nil {
+		return err
+	}
+
+	size := len(QRArray) + 2*options.QuietZone
+	pixels := float64(size) * options.ModuleSize
+	buffer := bufio.NewWriter(w)
+	// plain codes only have straight edges, antialiasing would leave seams between the runs
+	rendering := ""
+	if options.Style == nil {
+		rendering = ` shape-rendering="crispEdges"`
+	}
+	fmt.Fprintf(buffer, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%g" height="%g"%s>`+"\n", size, size, pixels, pixels, rendering)
+	if err := writeSVGCode(buffer, QRArray, options, colors); err != nil {
+		return err
+	}
+	buffer.WriteString("</svg>\n")
+	return buffer.Flush()
+}
+
+// checkSVGOptions sets the defaults on options and returns the colors to use
+func checkSVGOptions(options *SVGOptions) (Colors, error) {
+	if options.ModuleSize == 0 {
+		options.ModuleSize = defaultCellSize
+	}
+	if options.ModuleSize < 0 {
+		return Colors{}, errors.New("module size can not be negative")
+	}
+	if err := checkBitmapParams(1, options.QuietZone); err != nil {
+		return Colors{}, err
+	}
+	return getColors(options.Colors)
+}
+
+// writeSVGCode writes the elements of the code, quiet zone included, with its top left corner at 0, 0
+func writeSVGCode(buffer *bufio.Writer, QRArray [][]uint8, options SVGOptions, colors Colors) error {
+	quietZoneColor := colors.QuietZone
+	if quietZoneColor == nil {
+		quietZoneColor = colors.Light
+	}
+	size := len(QRArray) + 2*options.QuietZone
+	fmt.Fprintf(buffer, `<rect width="%d" height="%d" %s/>`+"\n", size, size, svgFill(quietZoneColor))
+	fmt.Fprintf(buffer, `<rect x="%d" y="%d" width="%d" height="%d" %s/>`+"\n", options.QuietZone, options.QuietZone, len(QRArray), len(QRArray), svgFill(colors.Light))
+
+	if options.Style != nil {
+		return writeStyledSVG(buffer, QRArray, options, colors)
+	}
+	var path strings.Builder
+	for i := range QRArray {
+		for _, run := range darkRuns(QRArray[i]) {
+			fmt.Fprintf(&path, "M%d %dh%dv1h%dz", run[0]+options.QuietZone, i+options.QuietZone, run[1]-run[0], run[0]-run[1])
+		}
+	}
+	fmt.Fprintf(buffer, `<path d="%s" %s/>`+"\n", path.String(), svgFill(colors.Dark))
+	return nil
+}
+
+func writeStyledSVG(buffer *bufio.Writer, QRArray [][]uint8, options SVGOptions, colors Colors) error {
+	roles, err := getRoles(QRArray, options.Roles)
+	if err != nil {
+		return err
+	}
+	options.Roles = roles
+	style := *options.Style
+	if err := style.Logo.check(len(QRArray)); err != nil {
+		return err
+	}
+	finder := style.Finder
+	if finder.OuterColor == nil {
+		finder.OuterColor = colors.Dark
+	}
+	if finder.InnerColor == nil {
+		finder.InnerColor = colors.Dark
+	}
+	for _, finderColor := range []color.Color{finder.OuterColor, finder.InnerColor} {
+		if err := CheckContrast(Colors{Dark: finderColor, Light: colors.Light, QuietZone: colors.QuietZone, Strict: colors.Strict, Reversed: colors.Reversed}); err != nil {
+			return err
+		}
+	}
+
+	quietZone := float64(options.QuietZone)
+	offset, side, radius := style.moduleGeometry()
+	// modules are grouped in one path per color, without a fill there is only one
+	paths := map[string]*strings.Builder{}
+	var fills []string
+	pathFor := func(i int, j int) *strings.Builder {
+		fill := svgFill(style.moduleColor(i, j, len(QRArray), colors))
+		if paths[fill] == nil {
+			paths[fill] = &strings.Builder{}
+			fills = append(fills, fill)
+		}
+		return paths[fill]
+	}
+	for i := range QRArray {
+		for j, cell := range QRArray[i] {
+			if options.Roles[i][j].IsFinder() || !isDark(cell) || style.Logo.covers(len(QRArray), i, j) {
+				continue
+			}
+			x := float64(j) + quietZone
+			y := float64(i) + quietZone
+			path := pathFor(i, j)
+			if options.Roles[i][j].IsFunctionPattern() {
+				path.WriteString(svgSquarePath(x, y, 1))
+				continue
+			}
+			switch style.ModuleShape {
+			case Shape_Connected:
+				path.WriteString(svgRoundedRectPath(x, y, 1, connectedRadii(QRArray, i, j, radius)))
+			default:
+				path.WriteString(svgShapePath(style.ModuleShape, x+offset, y+offset, side, radius*side))
+			}
+		}
+	}
+	for _, fill := range fills {
+		fmt.Fprintf(buffer, `<path d="%s" %s/>`+"\n", paths[fill].String(), fill)
+	}
+
+	if style.Logo != nil {
+		logo, err := svgLogo(len(QRArray), options.QuietZone, colors, style.Logo)
+		if err != nil {
+			return err
+		}
+		buffer.WriteString(logo)
+		// function patterns under the logo are needed to read the code, they go on top
+		area := style.Logo.logoArea(len(QRArray))
+		for i := area.Min.Y; i < area.Max.Y; i++ {
+			for j := area.Min.X; j < area.Max.X; j++ {
+				if options.Roles[i][j].IsFunctionPattern() && !options.Roles[i][j].IsFinder() && isDark(QRArray[i][j]) {
+					fmt.Fprintf(buffer, `<path d="%s" %s/>`+"\n", svgSquarePath(float64(j)+quietZone, float64(i)+quietZone, 1),
+						svgFill(style.moduleColor(i, j, len(QRArray), colors)))
+				}
+			}
+		}
+	}
+
+	for _, corner := range finderCorners(options.Roles) {
+		x := float64(corner.X) + quietZone
+		y := float64(corner.Y) + quietZone
+		ring := svgShapePath(finder.OuterShape, x, y, 7, finder.OuterRadius) + svgShapePath(finder.OuterShape, x+1, y+1, 5, math.Max(finder.OuterRadius-1, 0))
+		fmt.Fprintf(buffer, `<path d="%s" fill-rule="evenodd" %s/>`+"\n", ring, svgFill(finder.OuterColor))
+		fmt.Fprintf(buffer, `<path d="%s" %s/>`+"\n", svgShapePath(finder.InnerShape, x+2, y+2, 3, finder.InnerRadius), svgFill(finder.InnerColor))
+	}
+	return nil
+}
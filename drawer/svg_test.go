@@ -0,0 +1,37 @@
+package drawer
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestWriteSVGOptions(t *testing.T) {
+	QRArray := randomMatrix(21, 1)
+	tests := []struct {
+		name    string
+		options SVGOptions
+		wantErr bool
+	}{
+		{"defaults", SVGOptions{}, false},
+		{"colors", SVGOptions{Colors: &Colors{Dark: color.Black, Light: color.White}}, false},
+		{"no colors set", SVGOptions{Colors: &Colors{}}, true},
+		{"no light color", SVGOptions{Colors: &Colors{Dark: color.Black}}, true},
+		{"no dark color", SVGOptions{Colors: &Colors{Light: color.White}}, true},
+		{"negative module size", SVGOptions{ModuleSize: -1}, true},
+		{"negative quiet zone", SVGOptions{QuietZone: -1}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			err := WriteSVG(&buffer, QRArray, test.options)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err, test.wantErr)
+			}
+			if err == nil && (!strings.HasPrefix(buffer.String(), "<svg") || strings.Contains(buffer.String(), `width="-`)) {
+				t.Fatalf("unexpected SVG: %.100s", buffer.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package drawer
+
+import (
+	"QRCodeGenerator/generator"
+	"QRCodeGenerator/utils"
+	"errors"
+	"strconv"
+)
+
+// setModule writes a module of the template and records its role, roles is nil when
+// only the colors are needed
+func setModule(QRArray [][]uint8, roles [][]ModuleRole, i int, j int, color uint8, role ModuleRole) {
+	QRArray[i][j] = color
+	if roles != nil {
+		roles[i][j] = role
+	}
+}
+
+// drawSquarePattern draws a finder or alignment pattern, ringRoles has the role of
+// every ring counted from the center
+func drawSquarePattern(QRArray [][]uint8, roles [][]ModuleRole, x int, y int, radius int, ringRoles []ModuleRole) {
+	x0 := x - radius
+	y0 := y - radius
+	x1 := x + radius
+	y1 := y + radius
+
+	for i := x0; i <= x1; i++ {
+		for j := y0; j <= y1; j++ {
+			role := ringRoles[utils.GetMax(utils.Abs(i-x), utils.Abs(j-y))]
+
+			//midle white layer
+			if (j >= y0+1 && j <= y1-1 && (i == x0+1 || i == x1-1)) || ((j == y0+1 || j == y1-1) && i >= x0+1 && i <= x1-1) {
+				setModule(QRArray, roles, i, j, WHITE_COLOR, role)
+				continue
+			}
+
+			setModule(QRArray, roles, i, j, BLACK_COLOR, role)
+		}
+	}
+}
+
+func getPositionSquareCenters(size int) [][2]int {
+	return [][2]int{
+		{3, 3},        //upper left
+		{3, size - 4}, //upper right
+		{size - 4, 3}, //lower left
+	}
+}
+
+func addPositionSquare(QRArray [][]uint8, roles [][]ModuleRole, size int) {
+	ringRoles := []ModuleRole{ModuleRole_FinderInner, ModuleRole_FinderInner, ModuleRole_FinderGap, ModuleRole_FinderOuter}
+	for _, center := range getPositionSquareCenters(size) {
+		drawSquarePattern(QRArray, roles, center[0], center[1], 3, ringRoles)
+	}
+
+	//white borders (separators)
+	color := WHITE_COLOR
+	role := ModuleRole_Separator
+	for i := 0; i < 8; i++ {
+		//vertical borders
+		setModule(QRArray, roles, i, 7, color, role)
+		setModule(QRArray, roles, i, size-8, color, role)
+		setModule(QRArray, roles, size-8+i, 7, color, role)
+
+		//horizontal borders
+		setModule(QRArray, roles, 7, i, color, role)
+		setModule(QRArray, roles, 7, size-8+i, color, role)
+		setModule(QRArray, roles, size-8, i, color, role)
+	}
+
+}
+
+func addAlignSquares(QRArray [][]uint8, roles [][]ModuleRole, alignSquareCordenates []int) {
+	ringRoles := []ModuleRole{ModuleRole_Alignment, ModuleRole_Alignment, ModuleRole_Alignment}
+	for _, i := range alignSquareCordenates {
+		for _, j := range alignSquareCordenates {
+			if QRArray[i][j] == 0 {
+				drawSquarePattern(QRArray, roles, i, j, 2, ringRoles)
+			}
+		}
+	}
+}
+
+func addTiming(QRArray [][]uint8, roles [][]ModuleRole) {
+	size := len(QRArray)
+	color1 := BLACK_COLOR
+	color2 := BLACK_COLOR
+	for i := 0; i < size; i++ {
+		if (QRArray)[6][i] == 0 {
+			setModule(QRArray, roles, 6, i, uint8(color1), ModuleRole_Timing)
+			color1 = (color1 % 2) + 1
+		}
+		if (QRArray)[i][6] == 0 {
+			setModule(QRArray, roles, i, 6, uint8(color2), ModuleRole_Timing)
+			color2 = (color2 % 2) + 1
+		}
+	}
+}
+
+// AddFormatVersion writes the format information of the error level and mask, roles
+// is nil when only the colors are needed
+func AddFormatVersion(QRArray [][]uint8, roles [][]ModuleRole, errorLevel generator.ErrorLevel, maskPatern generator.MaskPattern, size int) {
+
+	informationString := generator.MaskPatternByErrorLevel
+
+	formatString := informationString[errorLevel][maskPatern]
+	binaryFormatString := utils.Byte16ToBoolArray(formatString)
+	binaryFormatString = binaryFormatString[1:] //only the last 15 are used
+
+	//left to right
+	for i, j := 0, 0; i < len(QRArray); i, j = i+1, j+1 {
+		if i == 6 {
+			i++
+		}
+		if j == 7 {
+			i = len(QRArray) - 8
+		}
+		if !binaryFormatString[j] {
+			setModule(QRArray, roles, 8, i, WHITE_COLOR, ModuleRole_Format)
+		} else {
+			setModule(QRArray, roles, 8, i, BLACK_COLOR, ModuleRole_Format)
+		}
+	}
+
+	//bottom to top
+	for i, j := 0, 0; i < len(QRArray); i, j = i+1, j+1 {
+		if j == 7 {
+			i = len(QRArray) - 9
+		}
+		if j == 9 {
+			i++
+		}
+		if !binaryFormatString[j] {
+			setModule(QRArray, roles, size-i-1, 8, WHITE_COLOR, ModuleRole_Format)
+		} else {
+			setModule(QRArray, roles, size-i-1, 8, BLACK_COLOR, ModuleRole_Format)
+		}
+	}
+}
+
+func addVersionInformation(QRArray [][]uint8, roles [][]ModuleRole, version int, size int) {
+	versionString := generator.VersionInformationString[version]
+	index := 0
+
+	if len(versionString) == 0 {
+		return
+	}
+
+	for i := range 6 {
+		for j := range 3 {
+			color := WHITE_COLOR
+			if versionString[index] {
+				color = BLACK_COLOR
+			}
+			setModule(QRArray, roles, 5-i, size-9-j, color, ModuleRole_Version)
+			setModule(QRArray, roles, size-9-i, 5-j, color, ModuleRole_Version)
+			index++
+		}
+	}
+}
+
+// GetQRTemplate draws the function patterns of the version, the modules left at 0 are
+// for the data. It also returns which pattern each module belongs to, every function
+// writes the role of the modules it draws
+func GetQRTemplate(QRVersionInfo generator.QRCodeInfo) ([][]uint8, [][]ModuleRole) {
+	QRArray := make([][]uint8, QRVersionInfo.Size)
+	roles := make([][]ModuleRole, QRVersionInfo.Size)
+	for i := 0; i < QRVersionInfo.Size; i++ {
+		QRArray[i] = make([]uint8, QRVersionInfo.Size)
+		roles[i] = make([]ModuleRole, QRVersionInfo.Size)
+	}
+
+	addPositionSquare(QRArray, roles, QRVersionInfo.Size)
+	addAlignSquares(QRArray, roles, QRVersionInfo.AlignSquareCordenates)
+	addTiming(QRArray, roles)
+	AddFormatVersion(QRArray, roles, QRVersionInfo.ErrorLevel, QRVersionInfo.MaskPatern, QRVersionInfo.Size)
+	addVersionInformation(QRArray, roles, QRVersionInfo.Version, QRVersionInfo.Size)
+	//add black square
+	setModule(QRArray, roles, QRVersionInfo.Size-8, 8, BLACK_COLOR, ModuleRole_DarkModule)
+
+	return QRArray, roles
+}
+
+// GetModuleRoles tells which pattern each module of a symbol of the size belongs to,
+// as GetQRTemplate draws them. The roles only depend on the version, so the error
+// level and mask of the symbol are not needed.
+func GetModuleRoles(size int) ([][]ModuleRole, error) {
+	version := (size - 17) / 4
+	if (size-17)%4 != 0 || version < 1 || version > generator.MAX_SUPPORTED_VERSION {
+		return nil, errors.New("no supported version has a size of " + strconv.Itoa(size))
+	}
+	_, roles := GetQRTemplate(generator.QRCodeInfo{
+		Version:               version,
+		Size:                  size,
+		AlignSquareCordenates: generator.QRAlignSquareCordinates[version],
+	})
+	return roles, nil
+}
@@ -0,0 +1,29 @@
+package drawer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WriteTikZ writes a tikzpicture with a filled rectangle for every run of dark modules,
+// moduleSize is a TeX dimension like "1mm" or "0.5em"
+func WriteTikZ(w io.Writer, QRArray [][]uint8, moduleSize string) error {
+	if moduleSize == "" {
+		moduleSize = "1mm"
+	}
+	size := len(QRArray)
+
+	buffer := bufio.NewWriter(w)
+	fmt.Fprintf(buffer, "\\begin{tikzpicture}[x=%s,y=%s]\n", moduleSize, moduleSize)
+	// keeps the light border of the code inside the bounding box
+	fmt.Fprintf(buffer, "  \\path[use as bounding box] (0,0) rectangle (%d,%d);\n", size, size)
+	for i := range QRArray {
+		for _, run := range darkRuns(QRArray[i]) {
+			// tikz has the y axis pointing up
+			fmt.Fprintf(buffer, "  \\fill (%d,%d) rectangle (%d,%d);\n", run[0], size-i-1, run[1], size-i)
+		}
+	}
+	buffer.WriteString("\\end{tikzpicture}\n")
+	return buffer.Flush()
+}
@@ -0,0 +1,101 @@
+package drawer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// LabelOptions are shared by the TSPL and EPL renderers. Gap and Density are pointers
+// because 0 is a valid value for both, nil gets the default.
+type LabelOptions struct {
+	LabelWidth  int  // in mm
+	LabelHeight int  // in mm
+	Gap         *int // gap between labels in mm, 0 for continuous media, 2 if nil
+	DotsPerMM   int  // 8 for 203 dpi printers, 12 for 300 dpi printers
+	X           int  // left position of the code in dots
+	Y           int  // top position of the code in dots
+	ModuleSize  int  // dots per module
+	QuietZone   int  // modules of margin around the code
+	Density     *int // darkness, 0 to 15, 8 if nil
+	Copies      int
+}
+
+func (options *LabelOptions) setDefaults() error {
+	if options.LabelWidth == 0 {
+		options.LabelWidth = 50
+	}
+	if options.LabelHeight == 0 {
+		options.LabelHeight = 50
+	}
+	if options.Gap == nil {
+		gap := 2
+		options.Gap = &gap
+	}
+	if options.DotsPerMM == 0 {
+		options.DotsPerMM = 8
+	}
+	if options.ModuleSize == 0 {
+		options.ModuleSize = 4
+	}
+	if options.Density == nil {
+		density := 8
+		options.Density = &density
+	}
+	if options.Copies == 0 {
+		options.Copies = 1
+	}
+	if *options.Gap < 0 {
+		return errors.New("gap can not be negative")
+	}
+	if *options.Density < 0 || *options.Density > 15 {
+		return errors.New("density must be between 0 and 15")
+	}
+	return checkBitmapParams(options.ModuleSize, options.QuietZone)
+}
+
+// checkFitsLabel makes sure a code of size dots placed on the options position is inside the label
+func (options *LabelOptions) checkFitsLabel(size int) error {
+	if options.X+size > options.LabelWidth*options.DotsPerMM || options.Y+size > options.LabelHeight*options.DotsPerMM {
+		return errors.New("QR code does not fit in the label")
+	}
+	return nil
+}
+
+// packRowInverted is packRow for printers where a 0 bit is the one that prints
+func packRowInverted(QRArray [][]uint8, scale int, quietZone int, y int) []byte {
+	row := packRow(QRArray, scale, quietZone, y, true)
+	for i := range row {
+		row[i] = ^row[i]
+	}
+	return row
+}
+
+// WriteTSPL writes a full label for TSC printers with the code as a BITMAP command
+func WriteTSPL(w io.Writer, QRArray [][]uint8, options LabelOptions) error {
+	if err := options.setDefaults(); err != nil {
+		return err
+	}
+	size := bitmapSize(QRArray, options.ModuleSize, options.QuietZone)
+	if err := options.checkFitsLabel(size); err != nil {
+		return err
+	}
+	bytesPerRow := (size + 7) / 8
+
+	buffer := bufio.NewWriter(w)
+	fmt.Fprintf(buffer, "SIZE %d mm,%d mm\r\n", options.LabelWidth, options.LabelHeight)
+	fmt.Fprintf(buffer, "GAP %d mm,0 mm\r\n", *options.Gap)
+	fmt.Fprintf(buffer, "DENSITY %d\r\n", *options.Density)
+	buffer.WriteString("CLS\r\n")
+
+	// mode 0 overwrites what is under the bitmap
+	fmt.Fprintf(buffer, "BITMAP %d,%d,%d,%d,0,", options.X, options.Y, bytesPerRow, size)
+	for y := range size {
+		buffer.Write(packRowInverted(QRArray, options.ModuleSize, options.QuietZone, y))
+	}
+	buffer.WriteString("\r\n")
+
+	fmt.Fprintf(buffer, "PRINT %d\r\n", options.Copies)
+	return buffer.Flush()
+}
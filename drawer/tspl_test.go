@@ -0,0 +1,53 @@
+package drawer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLabelGapAndDensity(t *testing.T) {
+	QRArray := randomMatrix(21, 3)
+	value := func(v int) *int {
+		return &v
+	}
+	tests := []struct {
+		name     string
+		options  LabelOptions
+		wantTSPL []string
+		wantEPL  []string
+		wantErr  bool
+	}{
+		{"defaults", LabelOptions{}, []string{"GAP 2 mm,0 mm\r\n", "DENSITY 8\r\n"}, []string{"\nQ400,16\n", "\nD8\n"}, false},
+		{"continuous media", LabelOptions{Gap: value(0)}, []string{"GAP 0 mm,0 mm\r\n"}, []string{"\nQ400,0\n"}, false},
+		{"lightest density", LabelOptions{Density: value(0)}, []string{"DENSITY 0\r\n"}, []string{"\nD0\n"}, false},
+		{"gap and density", LabelOptions{Gap: value(3), Density: value(15)}, []string{"GAP 3 mm,0 mm\r\n", "DENSITY 15\r\n"}, []string{"\nQ400,24\n", "\nD15\n"}, false},
+		{"negative gap", LabelOptions{Gap: value(-1)}, nil, nil, true},
+		{"density too high", LabelOptions{Density: value(16)}, nil, nil, true},
+		{"negative density", LabelOptions{Density: value(-1)}, nil, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			writers := []struct {
+				name  string
+				write func(*bytes.Buffer) error
+				want  []string
+			}{
+				{"TSPL", func(buffer *bytes.Buffer) error { return WriteTSPL(buffer, QRArray, test.options) }, test.wantTSPL},
+				{"EPL", func(buffer *bytes.Buffer) error { return WriteEPL(buffer, QRArray, test.options) }, test.wantEPL},
+			}
+			for _, writer := range writers {
+				var buffer bytes.Buffer
+				err := writer.write(&buffer)
+				if (err != nil) != test.wantErr {
+					t.Fatalf("%s: got error %v, want error %v", writer.name, err, test.wantErr)
+				}
+				for _, want := range writer.want {
+					if !strings.Contains(buffer.String(), want) {
+						t.Errorf("%s: output has no %q", writer.name, want)
+					}
+				}
+			}
+		})
+	}
+}
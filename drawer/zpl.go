@@ -0,0 +1,105 @@
+package drawer
+
+import (
+	"QRCodeGenerator/generator"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type ZPLTextPosition uint8
+
+const (
+	ZPLText_Right ZPLTextPosition = iota
+	ZPLText_Below
+)
+
+type ZPLOptions struct {
+	X             int  // left position of the code in dots
+	Y             int  // top position of the code in dots
+	Magnification int  // dots per module, 1 to 10
+	Native        bool // let the printer encode the code with ^BQ instead of sending the matrix
+	QuietZone     int  // modules of margin added to the ^GF graphic
+
+	Text         []string // human readable lines printed next to the code
+	TextPosition ZPLTextPosition
+	FontHeight   int // in dots
+}
+
+// escapeZPLField replaces the characters that ZPL would take as commands,
+// it must be used together with ^FH
+func escapeZPLField(s string) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '^', '~', '_':
+			fmt.Fprintf(&builder, "_%02X", s[i])
+		default:
+			builder.WriteByte(s[i])
+		}
+	}
+	return builder.String()
+}
+
+// WriteZPL writes a full ^XA..^XZ label with the QR code, with options.Native
+// the payload in QRversion.InfoToEncode is sent to the printer, otherwise the
+// exact QRArray is sent as a ^GF graphic field
+func WriteZPL(w io.Writer, QRArray [][]uint8, QRversion generator.QRCodeInfo, options ZPLOptions) error {
+	if options.Magnification == 0 {
+		options.Magnification = 4
+	}
+	if options.Magnification < 1 || options.Magnification > 10 {
+		return errors.New("magnification must be between 1 and 10")
+	}
+	if options.FontHeight == 0 {
+		options.FontHeight = 30
+	}
+	if err := checkBitmapParams(options.Magnification, options.QuietZone); err != nil {
+		return err
+	}
+
+	if options.Native && QRversion.InfoToEncode == "" {
+		return errors.New("native ZPL codes need the data to encode")
+	}
+
+	buffer := bufio.NewWriter(w)
+	buffer.WriteString("^XA\n")
+
+	var codeSize int
+	if options.Native {
+		codeSize = QRversion.Size * options.Magnification
+		errorLevel := QRversion.ErrorLevel.Letter()
+		// the error level on the field data is the one the printer uses,
+		// the one on ^BQ is kept for readability
+		fmt.Fprintf(buffer, "^FO%d,%d^BQN,2,%d,%s\n", options.X, options.Y, options.Magnification, errorLevel)
+		fmt.Fprintf(buffer, "^FH_^FD%sA,%s^FS\n", errorLevel, escapeZPLField(QRversion.InfoToEncode))
+	} else {
+		codeSize = bitmapSize(QRArray, options.Magnification, options.QuietZone)
+		bytesPerRow := (codeSize + 7) / 8
+		totalBytes := bytesPerRow * codeSize
+		fmt.Fprintf(buffer, "^FO%d,%d^GFA,%d,%d,%d,\n", options.X, options.Y, totalBytes, totalBytes, bytesPerRow)
+		for y := range codeSize {
+			fmt.Fprintf(buffer, "%X\n", packRow(QRArray, options.Magnification, options.QuietZone, y, true))
+		}
+		buffer.WriteString("^FS\n")
+	}
+
+	gap := options.Magnification * 2
+	for index, line := range options.Text {
+		var x, y int
+		switch options.TextPosition {
+		case ZPLText_Right:
+			x = options.X + codeSize + gap
+			y = options.Y + index*(options.FontHeight+gap)
+		case ZPLText_Below:
+			x = options.X
+			y = options.Y + codeSize + gap + index*(options.FontHeight+gap)
+		}
+		fmt.Fprintf(buffer, "^FO%d,%d^A0N,%d,%d^FH_^FD%s^FS\n", x, y, options.FontHeight, options.FontHeight, escapeZPLField(line))
+	}
+
+	buffer.WriteString("^XZ\n")
+	return buffer.Flush()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"QRCodeGenerator/generator"
+	"fmt"
+	"testing"
+)
+
+// readNumeric reads the digits of a numeric segment back: the character count, then 10 bits
+// for every 3 digits and 7 or 4 bits for the last 2 or 1
+func readNumeric(info QRCodeInfo, bits []bool) string {
+	read := func(length int) int {
+		value := 0
+		for _, bit := range bits[:length] {
+			value <<= 1
+			if bit {
+				value |= 1
+			}
+		}
+		bits = bits[length:]
+		return value
+	}
+	count := read(int(generator.GetCharacterCountIndicator(info.Version, info.EncodingMode)))
+	digits := ""
+	for ; count >= 3; count -= 3 {
+		digits += fmt.Sprintf("%03d", read(10))
+	}
+	switch count {
+	case 2:
+		digits += fmt.Sprintf("%02d", read(7))
+	case 1:
+		digits += fmt.Sprintf("%d", read(4))
+	}
+	return digits
+}
+
+func TestNumericKeepsLeadingZeros(t *testing.T) {
+	for _, data := range []string{"0012", "0", "00", "000", "0000000", "01234567", "1000", "9007"} {
+		info, err := getQRInfoByData(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.EncodingMode != generator.EncodingMode_Numeric {
+			t.Fatalf("%q is not encoded as numeric", data)
+		}
+		bits := append(getCharacterCount_Binary(info), getString_Encoded_Numeric(info)...)
+		if got := readNumeric(info, bits); got != data {
+			t.Errorf("%q reads back as %q", data, got)
+		}
+	}
+}
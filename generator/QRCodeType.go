@@ -1,5 +1,7 @@
 package generator
 
+import "errors"
+
 type EncodingMode uint8
 
 const (
@@ -63,6 +65,9 @@ type ERCodeWords struct {
 
 const MAX_SUPPORTED_VERSION = 7
 
+// GENERATOR_VERSION is written in the files that store the generation parameters
+const GENERATOR_VERSION = "0.2.0"
+
 var QRVersionInfo = map[int]map[ErrorLevel]QRCapacity{
 	1: {
 		ErrorLevel_L: QRCapacity{Numeric: 41, AlphaNumeric: 25, Binary: 17, Kanji: 10},
@@ -237,14 +242,14 @@ var ErrorCorrectionCodeWords = map[int]map[ErrorLevel]ERCodeWords{
 	3: {
 		ErrorLevel_L: ERCodeWords{Total: 55, ECCWPerBlock: 15, BlocksGroup1: 1, DataCodeWordsPerGroup1: 55, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
 		ErrorLevel_M: ERCodeWords{Total: 44, ECCWPerBlock: 26, BlocksGroup1: 1, DataCodeWordsPerGroup1: 44, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
-		ErrorLevel_Q: ERCodeWords{Total: 34, ECCWPerBlock: 18, BlocksGroup1: 1, DataCodeWordsPerGroup1: 17, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
-		ErrorLevel_H: ERCodeWords{Total: 26, ECCWPerBlock: 22, BlocksGroup1: 1, DataCodeWordsPerGroup1: 13, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
+		ErrorLevel_Q: ERCodeWords{Total: 34, ECCWPerBlock: 18, BlocksGroup1: 2, DataCodeWordsPerGroup1: 17, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
+		ErrorLevel_H: ERCodeWords{Total: 26, ECCWPerBlock: 22, BlocksGroup1: 2, DataCodeWordsPerGroup1: 13, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
 	},
 	4: {
 		ErrorLevel_L: ERCodeWords{Total: 80, ECCWPerBlock: 20, BlocksGroup1: 1, DataCodeWordsPerGroup1: 80, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
 		ErrorLevel_M: ERCodeWords{Total: 64, ECCWPerBlock: 18, BlocksGroup1: 2, DataCodeWordsPerGroup1: 32, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
 		ErrorLevel_Q: ERCodeWords{Total: 48, ECCWPerBlock: 26, BlocksGroup1: 2, DataCodeWordsPerGroup1: 24, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
-		ErrorLevel_H: ERCodeWords{Total: 36, ECCWPerBlock: 16, BlocksGroup1: 1, DataCodeWordsPerGroup1: 9, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
+		ErrorLevel_H: ERCodeWords{Total: 36, ECCWPerBlock: 16, BlocksGroup1: 4, DataCodeWordsPerGroup1: 9, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
 	},
 	5: {
 		ErrorLevel_L: ERCodeWords{Total: 108, ECCWPerBlock: 26, BlocksGroup1: 1, DataCodeWordsPerGroup1: 108, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
@@ -262,7 +267,7 @@ var ErrorCorrectionCodeWords = map[int]map[ErrorLevel]ERCodeWords{
 		ErrorLevel_L: ERCodeWords{Total: 156, ECCWPerBlock: 20, BlocksGroup1: 2, DataCodeWordsPerGroup1: 78, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
 		ErrorLevel_M: ERCodeWords{Total: 124, ECCWPerBlock: 18, BlocksGroup1: 4, DataCodeWordsPerGroup1: 31, BlocksGroup2: 0, DataCodeWordsPerGroup2: 0},
 		ErrorLevel_Q: ERCodeWords{Total: 88, ECCWPerBlock: 18, BlocksGroup1: 2, DataCodeWordsPerGroup1: 14, BlocksGroup2: 4, DataCodeWordsPerGroup2: 15},
-		ErrorLevel_H: ERCodeWords{Total: 66, ECCWPerBlock: 26, BlocksGroup1: 2, DataCodeWordsPerGroup1: 13, BlocksGroup2: 1, DataCodeWordsPerGroup2: 14},
+		ErrorLevel_H: ERCodeWords{Total: 66, ECCWPerBlock: 26, BlocksGroup1: 4, DataCodeWordsPerGroup1: 13, BlocksGroup2: 1, DataCodeWordsPerGroup2: 14},
 	},
 }
 
@@ -421,3 +426,37 @@ func (b EncodingMode) String() string {
 	}
 	return "Error" //should never happen
 }
+
+// Letter returns the single character used by the spec to name the level
+func (b ErrorLevel) Letter() string {
+	switch b {
+	case ErrorLevel_H:
+		return "H"
+	case ErrorLevel_L:
+		return "L"
+	case ErrorLevel_Q:
+		return "Q"
+	case ErrorLevel_M:
+		return "M"
+	}
+	return "" //should never happen
+}
+
+func ParseErrorLevel(s string) (ErrorLevel, error) {
+	for _, errorLevel := range GetErrorLevels() {
+		if errorLevel.Letter() == s {
+			return errorLevel, nil
+		}
+	}
+	return 0, errors.New("unknown error level: " + s)
+}
+
+func ParseEncodingMode(s string) (EncodingMode, error) {
+	encodingModes := []EncodingMode{EncodingMode_Numeric, EncodingMode_Alpha, EncodingMode_Byte, EncodingMode_Kanji, EncodingMode_ECI}
+	for _, encodingMode := range encodingModes {
+		if encodingMode.String() == s {
+			return encodingMode, nil
+		}
+	}
+	return 0, errors.New("unknown encoding mode: " + s)
+}
@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"fmt"
+	"testing"
+)
+
+// specBlocks is the error correction block structure of ISO/IEC 18004 table 9, as
+// (blocks, data codewords per block) for every group and the EC codewords per block
+type specBlocks struct {
+	ecPerBlock int
+	groups     [][2]int
+}
+
+var specErrorCorrection = map[int]map[ErrorLevel]specBlocks{
+	1: {ErrorLevel_L: {7, [][2]int{{1, 19}}}, ErrorLevel_M: {10, [][2]int{{1, 16}}}, ErrorLevel_Q: {13, [][2]int{{1, 13}}}, ErrorLevel_H: {17, [][2]int{{1, 9}}}},
+	2: {ErrorLevel_L: {10, [][2]int{{1, 34}}}, ErrorLevel_M: {16, [][2]int{{1, 28}}}, ErrorLevel_Q: {22, [][2]int{{1, 22}}}, ErrorLevel_H: {28, [][2]int{{1, 16}}}},
+	3: {ErrorLevel_L: {15, [][2]int{{1, 55}}}, ErrorLevel_M: {26, [][2]int{{1, 44}}}, ErrorLevel_Q: {18, [][2]int{{2, 17}}}, ErrorLevel_H: {22, [][2]int{{2, 13}}}},
+	4: {ErrorLevel_L: {20, [][2]int{{1, 80}}}, ErrorLevel_M: {18, [][2]int{{2, 32}}}, ErrorLevel_Q: {26, [][2]int{{2, 24}}}, ErrorLevel_H: {16, [][2]int{{4, 9}}}},
+	5: {ErrorLevel_L: {26, [][2]int{{1, 108}}}, ErrorLevel_M: {24, [][2]int{{2, 43}}}, ErrorLevel_Q: {18, [][2]int{{2, 15}, {2, 16}}}, ErrorLevel_H: {22, [][2]int{{2, 11}, {2, 12}}}},
+	6: {ErrorLevel_L: {18, [][2]int{{2, 68}}}, ErrorLevel_M: {16, [][2]int{{4, 27}}}, ErrorLevel_Q: {24, [][2]int{{4, 19}}}, ErrorLevel_H: {28, [][2]int{{4, 15}}}},
+	7: {ErrorLevel_L: {20, [][2]int{{2, 78}}}, ErrorLevel_M: {18, [][2]int{{4, 31}}}, ErrorLevel_Q: {18, [][2]int{{2, 14}, {4, 15}}}, ErrorLevel_H: {26, [][2]int{{4, 13}, {1, 14}}}},
+}
+
+// specTotalCodewords is the number of codewords of every version, table 1
+var specTotalCodewords = map[int]int{1: 26, 2: 44, 3: 70, 4: 100, 5: 134, 6: 172, 7: 196}
+
+func TestErrorCorrectionCodeWords(t *testing.T) {
+	for version := 1; version <= MAX_SUPPORTED_VERSION; version++ {
+		for _, errorLevel := range GetErrorLevels() {
+			codewords := ErrorCorrectionCodeWords[version][errorLevel]
+			spec := specErrorCorrection[version][errorLevel]
+			name := fmt.Sprintf("%d-%s", version, errorLevel.Letter())
+
+			want := ERCodeWords{ECCWPerBlock: spec.ecPerBlock, BlocksGroup1: spec.groups[0][0], DataCodeWordsPerGroup1: spec.groups[0][1]}
+			if len(spec.groups) > 1 {
+				want.BlocksGroup2, want.DataCodeWordsPerGroup2 = spec.groups[1][0], spec.groups[1][1]
+			}
+			want.Total = want.BlocksGroup1*want.DataCodeWordsPerGroup1 + want.BlocksGroup2*want.DataCodeWordsPerGroup2
+			if codewords != want {
+				t.Errorf("version %s: got %+v, want %+v", name, codewords, want)
+			}
+
+			blocks := codewords.BlocksGroup1 + codewords.BlocksGroup2
+			if total := codewords.Total + blocks*codewords.ECCWPerBlock; total != specTotalCodewords[version] {
+				t.Errorf("version %s: data and error correction make %d codewords, the version has %d", name, total, specTotalCodewords[version])
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/generator"
+	"errors"
+	"image"
+	"io"
+)
+
+// getQRInfoForHalftone works like getQRInfoByData but only uses error levels Q and H,
+// the background hides part of every module so the code needs more correction
+func getQRInfoForHalftone(stringToEncode string) (QRCodeInfo, error) {
+	for version := 1; version < generator.MAX_SUPPORTED_VERSION+1; version++ {
+		for _, errorLevel := range []ErrorLevel{generator.ErrorLevel_H, generator.ErrorLevel_Q} {
+			if QRInfo, ok := getQRInfoByVersion(stringToEncode, version, errorLevel); ok {
+				return QRInfo, nil
+			}
+		}
+	}
+	return QRCodeInfo{}, errors.New("data too long for a halftone code")
+}
+
+// renderHalftoneQR writes a picture code of stringToEncode over background, the matrix
+// is the same generateQR makes, with the mask chosen by getBestMaskPattern
+func renderHalftoneQR(w io.Writer, stringToEncode string, background image.Image, pixelSize int) error {
+	QRversion, err := getQRInfoForHalftone(stringToEncode)
+	if err != nil {
+		return err
+	}
+	QRArray := generateQR(&QRversion, QR_CODE_STEP_MASK)
+	return drawer.RenderHalftonePNG(w, QRArray, drawer.HalftoneOptions{
+		Background: background,
+		PixelSize:  pixelSize,
+		QuietZone:  4,
+	})
+}
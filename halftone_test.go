@@ -0,0 +1,89 @@
+package main
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/generator"
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestQRInfoForHalftone(t *testing.T) {
+	for _, data := range []string{"1234", "HELLO WORLD", "https://example.com/halftone"} {
+		info, err := getQRInfoForHalftone(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.ErrorLevel != generator.ErrorLevel_H && info.ErrorLevel != generator.ErrorLevel_Q {
+			t.Errorf("%q: got error level %s, want Q or H", data, info.ErrorLevel.Letter())
+		}
+	}
+	if _, err := getQRInfoForHalftone(strings.Repeat("x", 500)); err == nil {
+		t.Error("data too long for any version did not fail")
+	}
+}
+
+func TestHalftoneKeepsModules(t *testing.T) {
+	const pixelSize, quietZone = 2, 4
+	backgrounds := map[string]*image.Gray{
+		"black":    image.NewGray(image.Rect(0, 0, 64, 64)),
+		"white":    image.NewGray(image.Rect(0, 0, 64, 64)),
+		"gradient": image.NewGray(image.Rect(0, 0, 64, 64)),
+	}
+	for y := range 64 {
+		for x := range 64 {
+			backgrounds["white"].SetGray(x, y, color.Gray{255})
+			backgrounds["gradient"].SetGray(x, y, color.Gray{uint8(x * 4)})
+		}
+	}
+
+	const data = "https://example.com/halftone"
+	info, err := getQRInfoForHalftone(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	QRArray := generateQR(&info, QR_CODE_STEP_MASK)
+	_, roles := drawer.GetQRTemplate(info)
+	for name, background := range backgrounds {
+		t.Run(name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := renderHalftoneQR(&buffer, data, background, pixelSize); err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(&buffer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dark := func(y int, x int) bool {
+				gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+				return gray.Y < 128
+			}
+			moduleSide := drawer.HALFTONE_SUBMODULES * pixelSize
+			for i := range QRArray {
+				for j := range QRArray[i] {
+					y0 := (i + quietZone) * moduleSide
+					x0 := (j + quietZone) * moduleSide
+					want := QRArray[i][j] == drawer.BLACK_COLOR
+					center := moduleSide / 2
+					if dark(y0+center, x0+center) != want {
+						t.Fatalf("the center of module (%d, %d) lost its value", i, j)
+					}
+					if !roles[i][j].IsFunctionPattern() {
+						continue
+					}
+					// function patterns are solid so the code can be found
+					for y := y0; y < y0+moduleSide; y++ {
+						for x := x0; x < x0+moduleSide; x++ {
+							if dark(y, x) != want {
+								t.Fatalf("function pattern module (%d, %d) is not solid", i, j)
+							}
+						}
+					}
+				}
+			}
+		})
+	}
+}
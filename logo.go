@@ -0,0 +1,133 @@
+package main
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/generator"
+	"QRCodeGenerator/utils"
+	"errors"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strconv"
+)
+
+// LOGO_MAX_EC_USAGE is the part of the correction capacity of every block a logo can
+// use, the rest is left for print defects and bad lighting
+const LOGO_MAX_EC_USAGE = 0.6
+
+// getLogoSide returns the side in modules of a logo taking logoRatio of the code
+// width, sides are odd so the logo is centered on a module
+func getLogoSide(size int, logoRatio float64) int {
+	side := int(logoRatio * float64(size))
+	if side%2 == 0 {
+		side--
+	}
+	return utils.GetMax(side, 0)
+}
+
+// getMaxLogoSize returns the side in modules of the biggest centered logo the code can
+// lose without going over LOGO_MAX_EC_USAGE of the correction capacity of any block.
+// The logo can not cover finder, timing, format or version modules, the alignment
+// patterns under it are drawn over the logo.
+func getMaxLogoSize(QRVersionInfo QRCodeInfo) int {
+	size := QRVersionInfo.Size
+	codewords := getInterleavedCodewords(QRVersionInfo.CodeWords)
+	codewordIndexes := getCodewordIndexes(QRVersionInfo)
+	_, roles := drawer.GetQRTemplate(QRVersionInfo)
+	maxErrorsPerBlock := int(float64(QRVersionInfo.CodeWords.ECCWPerBlock/2) * LOGO_MAX_EC_USAGE)
+
+	maxSide := 0
+	for side := 1; side <= size; side += 2 {
+		start := (size - side) / 2
+		damaged := map[int]bool{}
+		errorsPerBlock := make([]int, QRVersionInfo.CodeWords.BlocksGroup1+QRVersionInfo.CodeWords.BlocksGroup2)
+
+		for i := start; i < start+side; i++ {
+			for j := start; j < start+side; j++ {
+				role := roles[i][j]
+				if role.IsFunctionPattern() && role != drawer.ModuleRole_Alignment {
+					return maxSide
+				}
+				codeword := codewordIndexes[i][j]
+				if codeword < 0 || damaged[codeword] {
+					continue
+				}
+				damaged[codeword] = true
+				errorsPerBlock[codewords[codeword].Block]++
+				if errorsPerBlock[codewords[codeword].Block] > maxErrorsPerBlock {
+					return maxSide
+				}
+			}
+		}
+		maxSide = side
+	}
+	return maxSide
+}
+
+// getQRInfoForLogo returns the info for a code that can hold a centered logo taking
+// logoRatio of its width, and the logo side in modules. When the logo is too big for the
+// error level getQRInfoByData picks, bigger versions are tried if bumpErrorLevel is set,
+// starting from their highest error level.
+func getQRInfoForLogo(stringToEncode string, logoRatio float64, bumpErrorLevel bool) (QRCodeInfo, int, error) {
+	if logoRatio <= 0 || logoRatio >= 1 {
+		return QRCodeInfo{}, 0, errors.New("logo ratio must be between 0 and 1")
+	}
+
+	QRInfo, err := getQRInfoByData(stringToEncode)
+	if err != nil {
+		return QRCodeInfo{}, 0, err
+	}
+	logoSide := getLogoSide(QRInfo.Size, logoRatio)
+	maxLogoSide := getMaxLogoSize(QRInfo)
+	if logoSide <= maxLogoSide {
+		return QRInfo, logoSide, nil
+	}
+	if !bumpErrorLevel {
+		return QRCodeInfo{}, 0, errors.New("logo too big for error level " + QRInfo.ErrorLevel.Letter() + ", it takes " +
+			strconv.Itoa(logoSide) + " modules and the limit is " + strconv.Itoa(maxLogoSide))
+	}
+
+	errorLevels := generator.GetErrorLevels()
+	for version := QRInfo.Version + 1; version < generator.MAX_SUPPORTED_VERSION+1; version++ {
+		for errorLevelIndex := len(errorLevels) - 1; errorLevelIndex >= 0; errorLevelIndex-- {
+			candidate, ok := getQRInfoByVersion(stringToEncode, version, errorLevels[errorLevelIndex])
+			if !ok {
+				continue
+			}
+			logoSide = getLogoSide(candidate.Size, logoRatio)
+			if logoSide <= getMaxLogoSize(candidate) {
+				return candidate, logoSide, nil
+			}
+		}
+	}
+	return QRCodeInfo{}, 0, errors.New("no version can hold a logo of that size")
+}
+
+// renderLogoQR writes a PNG of stringToEncode with logo in its center taking logoRatio of
+// the code width, the version and error level are the ones getQRInfoForLogo picks
+func renderLogoQR(w io.Writer, stringToEncode string, logo image.Image, logoRatio float64) error {
+	QRversion, logoSide, err := getQRInfoForLogo(stringToEncode, logoRatio, true)
+	if err != nil {
+		return err
+	}
+	QRArray := generateQR(&QRversion, QR_CODE_STEP_MASK)
+	return drawer.RenderPNG(w, QRArray, drawer.PNGOptions{
+		QuietZone: 4,
+		DPI:       drawer.DEFAULT_DPI,
+		Style:     &drawer.Style{Logo: &drawer.Logo{Image: logo, Modules: logoSide, ClearBackground: true}},
+		Metadata:  &QRversion,
+	})
+}
+
+// readImage decodes a PNG or JPEG file
+func readImage(fileName string) (image.Image, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	return img, err
+}
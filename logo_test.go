@@ -0,0 +1,109 @@
+package main
+
+import (
+	"QRCodeGenerator/drawer"
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestQRInfoForLogo(t *testing.T) {
+	const data = "https://example.com"
+	base, err := getQRInfoByData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		logoRatio      float64
+		bumpErrorLevel bool
+		wantErr        bool
+		wantBase       bool // the info is the one getQRInfoByData picks
+	}{
+		{"no logo", 0, false, true, false},
+		{"whole code", 1, false, true, false},
+		{"small logo", 0.1, false, false, true},
+		{"small logo with bump", 0.1, true, false, true},
+		{"too big for the error level", 0.3, false, true, false},
+		{"bigger version", 0.3, true, false, false},
+		{"too big for any version", 0.5, true, true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info, logoSide, err := getQRInfoForLogo(data, test.logoRatio, test.bumpErrorLevel)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if logoSide != getLogoSide(info.Size, test.logoRatio) || logoSide%2 != 1 {
+				t.Errorf("logo side %d for ratio %g of a %d modules code", logoSide, test.logoRatio, info.Size)
+			}
+			if maxLogoSide := getMaxLogoSize(info); logoSide > maxLogoSide {
+				t.Errorf("logo side %d is over the limit %d of version %d-%s", logoSide, maxLogoSide, info.Version, info.ErrorLevel.Letter())
+			}
+			if isBase := info.Version == base.Version && info.ErrorLevel == base.ErrorLevel; isBase != test.wantBase {
+				t.Errorf("got version %d-%s, getQRInfoByData picks %d-%s", info.Version, info.ErrorLevel.Letter(), base.Version, base.ErrorLevel.Letter())
+			}
+		})
+	}
+}
+
+func TestMaxLogoSizeSkipsFunctionPatterns(t *testing.T) {
+	for _, info := range getTestQRInfo(t) {
+		side := getMaxLogoSize(info)
+		_, roles := drawer.GetQRTemplate(info)
+		start := (info.Size - side) / 2
+		for i := start; i < start+side; i++ {
+			for j := start; j < start+side; j++ {
+				// alignment patterns are drawn again over the logo
+				if role := roles[i][j]; role.IsFunctionPattern() && role != drawer.ModuleRole_Alignment {
+					t.Fatalf("version %d-%s: a logo of %d modules covers module (%d, %d) with role %d", info.Version, info.ErrorLevel.Letter(), side, i, j, role)
+				}
+			}
+		}
+	}
+}
+
+func TestRenderLogoQR(t *testing.T) {
+	const data, logoRatio = "https://example.com", 0.3
+	logo := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for y := range 16 {
+		for x := range 16 {
+			logo.Set(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+	info, logoSide, err := getQRInfoForLogo(data, logoRatio, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	if err := renderLogoQR(&buffer, data, logo, logoRatio); err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := drawer.ReadPNGMetadata(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Version != info.Version || metadata.ErrorLevel != info.ErrorLevel {
+		t.Errorf("got version %d-%s, want %d-%s", metadata.Version, metadata.ErrorLevel.Letter(), info.Version, info.ErrorLevel.Letter())
+	}
+	img, err := png.Decode(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the logo is drawn on the center module, 10 pixels per module and 4 of quiet zone
+	center := (info.Size/2+4)*10 + 5
+	if r, g, b, _ := img.At(center, center).RGBA(); r>>8 != 255 || g != 0 || b != 0 {
+		t.Errorf("the center of a %d modules logo is not the logo", logoSide)
+	}
+
+	if err := renderLogoQR(&buffer, data, logo, 0.5); err == nil {
+		t.Error("a logo too big for any version did not fail")
+	}
+}
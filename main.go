@@ -6,7 +6,10 @@ import (
 	logger "QRCodeGenerator/logger"
 	"QRCodeGenerator/utils"
 	"errors"
-	"math"
+	"flag"
+	"image"
+	"io"
+	"os"
 	"strconv"
 	"strings"
 )
@@ -48,38 +51,41 @@ func getEncodeMode(data string) generator.EncodingMode {
 	return generator.EncodingMode_Byte
 }
 
-func getQRInfoByData(stringToEncode string) (QRCodeInfo, error) {
-
-	QRVersionInfo := generator.QRVersionInfo
-	QRAlignSquareCordinates := generator.QRAlignSquareCordinates
+// getQRInfoByVersion returns the info for the given version and error level,
+// the bool is false if the data does not fit
+func getQRInfoByVersion(stringToEncode string, version int, errorLevel ErrorLevel) (QRCodeInfo, bool) {
 	encodedMode := getEncodeMode(stringToEncode)
+	capacity := generator.QRVersionInfo[version]
+	errorLevelCapacity := getCapacityByEncodeMode(capacity[errorLevel], encodedMode)
+	QRCodeWords := generator.ErrorCorrectionCodeWords[version][errorLevel]
+
+	if errorLevelCapacity < len(stringToEncode) {
+		return QRCodeInfo{}, false
+	}
+
+	QRInfo := QRCodeInfo{
+		Version:               version,
+		Size:                  4*version + 17,
+		ErrorLevel:            errorLevel,
+		MaskPatern:            generator.MaskPattern_2, // esto se pisara mas adelante
+		InfoToEncode:          stringToEncode,
+		EncodingMode:          encodedMode,
+		BitsPerData:           generator.GetCharacterCountIndicator(version, encodedMode),
+		CodeWords:             QRCodeWords,
+		AlignSquareCordenates: generator.QRAlignSquareCordinates[version],
+		MaxNumberOfBits:       errorLevelCapacity,
+	}
+	return QRInfo, true
+}
+
+func getQRInfoByData(stringToEncode string) (QRCodeInfo, error) {
 
 	errorLevels := generator.GetErrorLevels()
 	errorLevelsSize := len(errorLevels)
-	stringToEncodeSize := len(stringToEncode)
 
 	for version := 1; version < generator.MAX_SUPPORTED_VERSION+1; version++ {
-		capacity := QRVersionInfo[version]
-
 		for errorLevelIndex := errorLevelsSize - 1; errorLevelIndex >= 0; errorLevelIndex-- {
-
-			errorLevel := errorLevels[errorLevelIndex]
-			errorLevelCapacity := getCapacityByEncodeMode(capacity[errorLevel], encodedMode)
-			QRCodeWords := generator.ErrorCorrectionCodeWords[version][errorLevel]
-
-			if errorLevelCapacity >= stringToEncodeSize {
-				QRInfo := QRCodeInfo{
-					Version:               version,
-					Size:                  4*version + 17,
-					ErrorLevel:            errorLevel,
-					MaskPatern:            generator.MaskPattern_2, // esto se pisara mas adelante
-					InfoToEncode:          stringToEncode,
-					EncodingMode:          encodedMode,
-					BitsPerData:           generator.GetCharacterCountIndicator(version, encodedMode),
-					CodeWords:             QRCodeWords,
-					AlignSquareCordenates: QRAlignSquareCordinates[version],
-					MaxNumberOfBits:       errorLevelCapacity,
-				}
+			if QRInfo, ok := getQRInfoByVersion(stringToEncode, version, errorLevels[errorLevelIndex]); ok {
 				return QRInfo, nil
 			}
 		}
@@ -88,112 +94,6 @@ func getQRInfoByData(stringToEncode string) (QRCodeInfo, error) {
 	return QRCodeInfo{}, errors.New("no se encontro version compatible")
 }
 
-func drawSquarePattern(QRArray [][]uint8, x int, y int, radius int) {
-	x0 := x - radius
-	y0 := y - radius
-	x1 := x + radius
-	y1 := y + radius
-
-	for i := x0; i <= x1; i++ {
-		for j := y0; j <= y1; j++ {
-
-			//midle white layer
-			if (j >= y0+1 && j <= y1-1 && (i == x0+1 || i == x1-1)) || ((j == y0+1 || j == y1-1) && i >= x0+1 && i <= x1-1) {
-				QRArray[i][j] = drawer.WHITE_COLOR
-				continue
-			}
-
-			QRArray[i][j] = drawer.BLACK_COLOR
-		}
-	}
-}
-
-func addPositionSquare(QRArray [][]uint8, size int) {
-	drawSquarePattern(QRArray, 3, 3, 3)      //upper left
-	drawSquarePattern(QRArray, 3, size-4, 3) //upper right
-	drawSquarePattern(QRArray, size-4, 3, 3) //lower left
-
-	//white borders (separators)
-	color := drawer.WHITE_COLOR
-	for i := 0; i < 8; i++ {
-		//vertical borders
-		QRArray[i][7] = color
-		QRArray[i][size-8] = color
-		QRArray[size-8+i][7] = color
-
-		//horizontal borders
-		QRArray[7][i] = color
-		QRArray[7][size-8+i] = color
-		QRArray[size-8][i] = color
-	}
-
-}
-
-func addAlignSquares(QRArray [][]uint8, alignSquareCordenates []int) {
-	for _, i := range alignSquareCordenates {
-		for _, j := range alignSquareCordenates {
-			if QRArray[i][j] == 0 {
-				drawSquarePattern(QRArray, i, j, 2)
-			}
-		}
-	}
-}
-
-func addTiming(QRArray [][]uint8) {
-	size := len(QRArray)
-	color1 := drawer.BLACK_COLOR
-	color2 := drawer.BLACK_COLOR
-	for i := 0; i < size; i++ {
-		if (QRArray)[6][i] == 0 {
-			(QRArray)[6][i] = uint8(color1)
-			color1 = (color1 % 2) + 1
-		}
-		if (QRArray)[i][6] == 0 {
-			(QRArray)[i][6] = uint8(color2)
-			color2 = (color2 % 2) + 1
-		}
-	}
-}
-
-func addFormatVersion(QRArray [][]uint8, errorLevel ErrorLevel, maskPatern MaskPattern, size int) {
-
-	informationString := generator.MaskPatternByErrorLevel
-
-	formatString := informationString[errorLevel][maskPatern]
-	binaryFormatString := utils.Byte16ToBoolArray(formatString)
-	binaryFormatString = binaryFormatString[1:] //only the last 15 are used
-
-	//left to right
-	for i, j := 0, 0; i < len(QRArray); i, j = i+1, j+1 {
-		if i == 6 {
-			i++
-		}
-		if j == 7 {
-			i = len(QRArray) - 8
-		}
-		if !binaryFormatString[j] {
-			(QRArray)[8][i] = drawer.WHITE_COLOR
-		} else {
-			(QRArray)[8][i] = drawer.BLACK_COLOR
-		}
-	}
-
-	//bottom to top
-	for i, j := 0, 0; i < len(QRArray); i, j = i+1, j+1 {
-		if j == 7 {
-			i = len(QRArray) - 9
-		}
-		if j == 9 {
-			i++
-		}
-		if !binaryFormatString[j] {
-			(QRArray)[size-i-1][8] = drawer.WHITE_COLOR
-		} else {
-			(QRArray)[size-i-1][8] = drawer.BLACK_COLOR
-		}
-	}
-}
-
 func writeOrder(value int, index int) int {
 	if index%2 == 0 {
 		return value - 1
@@ -201,42 +101,8 @@ func writeOrder(value int, index int) int {
 	return value + 1
 }
 
-func addVersionInformation(QRArray [][]uint8, version int, size int) {
-	versionString := generator.VersionInformationString[version]
-	index := 0
-
-	if len(versionString) == 0 {
-		return
-	}
-
-	for i := range 6 {
-		for j := range 3 {
-			if versionString[index] {
-				QRArray[5-i][size-9-j] = drawer.BLACK_COLOR
-				QRArray[size-9-i][5-j] = drawer.BLACK_COLOR
-			} else {
-				QRArray[5-i][size-9-j] = drawer.WHITE_COLOR
-				QRArray[size-9-i][5-j] = drawer.WHITE_COLOR
-			}
-			index++
-		}
-	}
-}
-
 func generateQRTemplate(QRVersionInfo QRCodeInfo) [][]uint8 {
-	QRArray := make([][]uint8, QRVersionInfo.Size)
-	for i := 0; i < QRVersionInfo.Size; i++ {
-		QRArray[i] = make([]uint8, QRVersionInfo.Size)
-	}
-
-	addPositionSquare(QRArray, QRVersionInfo.Size)
-	addAlignSquares(QRArray, QRVersionInfo.AlignSquareCordenates)
-	addTiming(QRArray)
-	addFormatVersion(QRArray, QRVersionInfo.ErrorLevel, QRVersionInfo.MaskPatern, QRVersionInfo.Size)
-	addVersionInformation(QRArray, QRVersionInfo.Version, QRVersionInfo.Size)
-	//add black square
-	QRArray[QRVersionInfo.Size-8][8] = drawer.BLACK_COLOR
-
+	QRArray, _ := drawer.GetQRTemplate(QRVersionInfo)
 	return QRArray
 }
 
@@ -272,7 +138,8 @@ func getString_Encoded_Byte(QRVersionInfo QRCodeInfo) []bool {
 
 func getString_Encoded_Numeric(QRVersionInfo QRCodeInfo) []bool {
 	encodedString := make([]bool, 0, (len(QRVersionInfo.InfoToEncode)/3)*10)
-	numericString := strings.TrimLeft(QRVersionInfo.InfoToEncode, "0")
+	// leading zeros are part of the data, every group keeps its own when it is parsed
+	numericString := QRVersionInfo.InfoToEncode
 	extraNumbers := ""
 
 	if len(numericString)%3 != 0 {
@@ -338,52 +205,50 @@ func getString_Encoded(QRVersionInfo QRCodeInfo) []bool {
 	panic("Encode Mode not Found: " + QRVersionInfo.EncodingMode.String())
 }
 
-func addDataToQRCode(QRArray [][]uint8, QRVersionInfo QRCodeInfo, data []bool) [][]uint8 {
-	QRArrayCopy := utils.DeepCopy2D(QRArray)
-	index := 0
+// getDataModulePositions returns the free modules of the template in the order the data is written
+func getDataModulePositions(QRTemplate [][]uint8) [][2]int {
+	size := len(QRTemplate)
+	positions := make([][2]int, 0, size*size)
 	row := 0
-	j := QRVersionInfo.Size
-	for i := (QRVersionInfo.Size - 1); i >= 0; i -= 2 { //rigth to left
+	j := size
+	for i := (size - 1); i >= 0; i -= 2 { //rigth to left
 		j = writeOrder(j, row)
 		// ignore the left vertical timming
 		if i == 6 {
 			i--
 		}
-		for j >= 0 && j < QRVersionInfo.Size { //down to up or up to down
+		for j >= 0 && j < size { //down to up or up to down
 			for k := 0; k < 2; k++ {
-				if index >= len(data) {
-					// some versions have empty bites at the end between 7 and 0,
-					// for example versions 2 to 6 have 7 empty bites
-					if QRArrayCopy[j][i-k] != 0 {
-						// not re write used cells
-						continue
-					}
-					QRArrayCopy[j][i-k] = drawer.RED_COLOR // debug
-					continue
-				}
-
-				if i == 0 && k > 0 {
-					//last column right to left
-					continue
-				}
-
-				if QRArrayCopy[j][i-k] != 0 {
+				if QRTemplate[j][i-k] != 0 {
 					// not re write used cells
 					continue
 				}
-				if data[index] {
-					QRArrayCopy[j][i-k] = drawer.BLACK_COLOR
-				} else {
-					QRArrayCopy[j][i-k] = drawer.WHITE_COLOR
-				}
-
-				index++
+				positions = append(positions, [2]int{j, i - k})
 			}
 			j = writeOrder(j, row)
 		}
 		row++
 	}
-	if index < len(data) {
+	return positions
+}
+
+func addDataToQRCode(QRArray [][]uint8, QRVersionInfo QRCodeInfo, data []bool) [][]uint8 {
+	QRArrayCopy := utils.DeepCopy2D(QRArray)
+	positions := getDataModulePositions(QRArray)
+	for index, position := range positions {
+		if index >= len(data) {
+			// some versions have empty bites at the end between 7 and 0,
+			// for example versions 2 to 6 have 7 empty bites
+			QRArrayCopy[position[0]][position[1]] = drawer.RED_COLOR // debug
+			continue
+		}
+		if data[index] {
+			QRArrayCopy[position[0]][position[1]] = drawer.BLACK_COLOR
+		} else {
+			QRArrayCopy[position[0]][position[1]] = drawer.WHITE_COLOR
+		}
+	}
+	if len(data) > len(positions) {
 		logger.Info("X Error.")
 	}
 	return QRArrayCopy
@@ -473,7 +338,7 @@ func getStructuredFinalMessage(QRVersionInfo QRCodeInfo, dataCodeWords []bool, E
 	// get Data Codewords for Second Group
 	DCWInFirstGroup := QRVersionInfo.CodeWords.BlocksGroup1 * DCWsPerGroup1
 	for i := range blockGroup2 {
-		dataCodeWordsInGroups[i+DCWsPerGroup1] = dataCodeWords[i*DCWsPerGroup2+DCWInFirstGroup : (i+1)*DCWsPerGroup2+DCWInFirstGroup]
+		dataCodeWordsInGroups[i+blockGroup1] = dataCodeWords[i*DCWsPerGroup2+DCWInFirstGroup : (i+1)*DCWsPerGroup2+DCWInFirstGroup]
 	}
 
 	//intervale Data Code Words
@@ -481,7 +346,7 @@ func getStructuredFinalMessage(QRVersionInfo QRCodeInfo, dataCodeWords []bool, E
 	for i := range bigestCodeWrdsLenght {
 		for j := range len(dataCodeWordsInGroups) {
 			//groups have differente amount of data code words
-			if i < len(dataCodeWordsInGroups[j]) {
+			if i*8 < len(dataCodeWordsInGroups[j]) {
 				finalMessage = append(finalMessage, dataCodeWordsInGroups[j][i*8:(i+1)*8]...)
 			}
 		}
@@ -514,122 +379,23 @@ func applyMask(maskpatern MaskPattern, errorLevel ErrorLevel, QRTemplate [][]uin
 			}
 		}
 	}
-	addFormatVersion(QRFinal, errorLevel, maskpatern, len(QRTemplate))
+	drawer.AddFormatVersion(QRFinal, nil, errorLevel, maskpatern, len(QRTemplate))
 	return QRFinal
 }
 
 func getBestMaskPattern(QRVersionInfo QRCodeInfo, QRTemplate [][]uint8, QRFinal [][]uint8) MaskPattern {
-	maskPaterns := generator.GetMaskPatterns()
 	var bestMask MaskPattern
 	var lowestScore uint = 4294967295 //max uint
 	logger.Info("Finding best mask pattern")
 
-	for maskIndex := range len(maskPaterns) {
-		QRFinalCopy := utils.DeepCopy2D(QRFinal)
-		//overwrite the temporal mask infomration
-		addFormatVersion(QRFinalCopy, QRVersionInfo.ErrorLevel, maskPaterns[maskIndex], QRVersionInfo.Size)
-		QrArrayWithMask := applyMask(maskPaterns[maskIndex], QRVersionInfo.ErrorLevel, QRTemplate, QRFinalCopy)
-
-		penaltyPoints1 := uint(0)
-		penaltyPoints2 := uint(0)
-		penaltyPoints3 := uint(0)
-		penaltyPoints4 := uint(0)
-
-		continuousBlocksH := uint(0)
-		continuousBlocksV := uint(0)
-		currentColorH := QrArrayWithMask[0][0]
-		currentColorV := QrArrayWithMask[0][0]
-
-		PaternPenalty3 := []uint8{drawer.BLACK_COLOR, drawer.WHITE_COLOR, drawer.BLACK_COLOR, drawer.BLACK_COLOR, drawer.BLACK_COLOR, drawer.WHITE_COLOR, drawer.BLACK_COLOR, drawer.WHITE_COLOR, drawer.WHITE_COLOR, drawer.WHITE_COLOR, drawer.WHITE_COLOR}
-		PaternInvertedPenalty3 := []uint8{drawer.WHITE_COLOR, drawer.WHITE_COLOR, drawer.WHITE_COLOR, drawer.WHITE_COLOR, drawer.BLACK_COLOR, drawer.WHITE_COLOR, drawer.BLACK_COLOR, drawer.BLACK_COLOR, drawer.BLACK_COLOR, drawer.WHITE_COLOR, drawer.BLACK_COLOR}
-
-		blackScuares := 0
-		whiteSqueares := 0
-
-		for i := range QRVersionInfo.Size {
-			for j := range QRVersionInfo.Size {
-
-				//penalty rule 1
-				if QrArrayWithMask[i][j] == currentColorH {
-					continuousBlocksH++
-				} else {
-					if continuousBlocksH >= 3 {
-						penaltyPoints1 += continuousBlocksH - 2
-					}
-					continuousBlocksH = 1
-					currentColorH = QrArrayWithMask[i][j]
-				}
-
-				if QrArrayWithMask[j][i] == currentColorV {
-					continuousBlocksV++
-				} else {
-					if continuousBlocksV >= 3 {
-						penaltyPoints1 += continuousBlocksV - 2
-					}
-					continuousBlocksV = 1
-					currentColorV = QrArrayWithMask[j][i]
-				}
-
-				//penalty rule 2
-				if j < QRVersionInfo.Size-1 && i < QRVersionInfo.Size-1 {
-					if QrArrayWithMask[i][j] == QrArrayWithMask[i+1][j+1] && QrArrayWithMask[i][j] == QrArrayWithMask[i+1][j] && QrArrayWithMask[i][j] == QrArrayWithMask[i][j+1] {
-						penaltyPoints2 += 3
-					}
-				}
-
-				//penalty rule 3
-				if !(i > QRVersionInfo.Size-len(PaternPenalty3) && j > QRVersionInfo.Size-len(PaternPenalty3)) {
-					if i < QRVersionInfo.Size-len(PaternPenalty3) {
-						patternChecks := true
-						invertedPatternChecks := true
-						for k := 0; k < len(PaternPenalty3); k++ {
-							patternChecks = patternChecks && PaternPenalty3[k] == QrArrayWithMask[i+k][j]
-							invertedPatternChecks = invertedPatternChecks && PaternInvertedPenalty3[k] == QrArrayWithMask[i+k][j]
-						}
-						if patternChecks || invertedPatternChecks {
-							penaltyPoints3 += 40
-						}
-					}
-					if j < QRVersionInfo.Size-len(PaternPenalty3) {
-						patternChecks := true
-						invertedPatternChecks := true
-						for k := 0; k < len(PaternPenalty3); k++ {
-							patternChecks = patternChecks && PaternPenalty3[k] == QrArrayWithMask[i][j+k]
-							invertedPatternChecks = invertedPatternChecks && PaternInvertedPenalty3[k] == QrArrayWithMask[i][j+k]
-						}
-						if patternChecks || invertedPatternChecks {
-							penaltyPoints3 += 40
-						}
-					}
-				}
-
-				//penalty rule 4 part 1
-				if QrArrayWithMask[i][j] == drawer.BLACK_COLOR {
-					blackScuares++
-				} else {
-					whiteSqueares++
-				}
-			}
-		}
-
-		//penalty score 4 part 2
-		blackPercentaje := math.Floor((float64(blackScuares) / float64(whiteSqueares)) * 100)
-		mod5 := float64(int(blackPercentaje) % 5)
-		firstNumber := math.Abs(blackPercentaje-mod5-50) / 5
-		secondNumber := math.Abs(blackPercentaje+(5-mod5)-50) / 5
-		if firstNumber < secondNumber {
-			penaltyPoints4 = uint(firstNumber) * 10
-		} else {
-			penaltyPoints4 = uint(secondNumber) * 10
-		}
-
-		maskScore := penaltyPoints1 + penaltyPoints2 + penaltyPoints3 + penaltyPoints4
-		logger.Info("-- mask: ", maskPaterns[maskIndex], " value: ", maskScore)
+	penalties := getBitsetMaskPenalties(QRVersionInfo, QRTemplate, QRFinal)
+	for _, penalty := range penalties {
+		maskScore := penalty.Total()
+		logger.Info("-- mask: ", penalty.Mask, " value: ", maskScore, " (N1: ", penalty.N1, ", N2: ", penalty.N2, ", N3: ", penalty.N3, ", N4: ", penalty.N4, ")")
 		if maskScore < lowestScore {
-			bestMask = maskPaterns[maskIndex]
+			bestMask = penalty.Mask
 			lowestScore = maskScore
 		}
-
 	}
 	return bestMask
 }
@@ -639,53 +405,79 @@ func getReminderBits(QRVersionInfo QRCodeInfo) []bool {
 	return make([]bool, reminderBitsCount)
 }
 
-func generateQR(QRVersionInfo QRCodeInfo, QRCode_final_step uint8) [][]uint8 {
+// generateQR also stores the chosen mask pattern in QRVersionInfo
+func generateQR(QRVersionInfo *QRCodeInfo, QRCode_final_step uint8) [][]uint8 {
+	// PenaltyMaskSelector always finds a mask
+	QRArray, _ := generateQRWithMaskSelector(QRVersionInfo, QRCode_final_step, PenaltyMaskSelector{})
+	return QRArray
+}
 
-	QRArrayBase := generateQRTemplate(QRVersionInfo)
+// generateQRWithMaskSelector works like generateQR with the mask chosen by maskSelector,
+// it fails when the selector can not give a valid mask
+func generateQRWithMaskSelector(QRVersionInfo *QRCodeInfo, QRCode_final_step uint8, maskSelector MaskSelector) ([][]uint8, error) {
+
+	QRArrayBase := generateQRTemplate(*QRVersionInfo)
 	totalAmountOfBits := QRVersionInfo.CodeWords.Total * 8                                                                                        //codewords
 	totalAmountOfBits += (QRVersionInfo.CodeWords.BlocksGroup1 + QRVersionInfo.CodeWords.BlocksGroup1) * QRVersionInfo.CodeWords.ECCWPerBlock * 8 // error correction
 	data := make([]bool, 0, totalAmountOfBits)
 
-	data = append(data, getEncodeMode_Binary(QRVersionInfo)...)
+	data = append(data, getEncodeMode_Binary(*QRVersionInfo)...)
 
 	if QRCode_final_step >= QR_CODE_STEP_CHARACTER_COUNT {
-		data = append(data, getCharacterCount_Binary(QRVersionInfo)...)
+		data = append(data, getCharacterCount_Binary(*QRVersionInfo)...)
 	}
 
 	if QRCode_final_step >= QR_CODE_STEP_ENCODE_DATA {
-		data = append(data, getString_Encoded(QRVersionInfo)...)
-		data = append(data, getPadingBits_Binary(QRVersionInfo, len(data))...)
+		data = append(data, getString_Encoded(*QRVersionInfo)...)
+		data = append(data, getPadingBits_Binary(*QRVersionInfo, len(data))...)
 		logger.Info("✓ Data encoded.")
 	}
 
 	var ERcodewords [][]bool
 
 	if QRCode_final_step >= QR_CODE_STEP_ERROR_CORRECTION {
-		ERcodewords = getCodeWords_Encoded(QRVersionInfo, data)
+		ERcodewords = getCodeWords_Encoded(*QRVersionInfo, data)
 		logger.Info("✓ Created Error Correction Codewords.")
 	} else {
 		ERcodewords = [][]bool{}
 	}
 
-	encodedMessage := getStructuredFinalMessage(QRVersionInfo, data, ERcodewords)
+	encodedMessage := getStructuredFinalMessage(*QRVersionInfo, data, ERcodewords)
 
 	if QRCode_final_step >= QR_CODE_STEP_REMINDER_BITS {
-		encodedMessage = append(encodedMessage, getReminderBits(QRVersionInfo)...)
+		encodedMessage = append(encodedMessage, getReminderBits(*QRVersionInfo)...)
 		logger.Info("✓ Added Reminder bits.")
 	}
-	QRArrayWithData := addDataToQRCode(QRArrayBase, QRVersionInfo, encodedMessage)
+	QRArrayWithData := addDataToQRCode(QRArrayBase, *QRVersionInfo, encodedMessage)
 
 	if QRCode_final_step < QR_CODE_STEP_MASK {
-		return QRArrayWithData
+		return QRArrayWithData, nil
 	}
 
 	logger.Info("✓ Added code words to QR code.")
 
-	QRVersionInfo.MaskPatern = getBestMaskPattern(QRVersionInfo, QRArrayBase, QRArrayWithData)
-	logger.Info("✓ Got best mask pattern: ", QRVersionInfo.MaskPatern)
+	maskPatern, err := maskSelector.SelectMask(*QRVersionInfo, QRArrayBase, QRArrayWithData)
+	if err != nil {
+		return nil, err
+	}
+	QRVersionInfo.MaskPatern = maskPatern
+	logger.Info("✓ Got mask pattern: ", QRVersionInfo.MaskPatern)
 
 	QRArrayWithMask := applyMask(QRVersionInfo.MaskPatern, QRVersionInfo.ErrorLevel, QRArrayBase, QRArrayWithData)
-	return QRArrayWithMask
+	return QRArrayWithMask, nil
+}
+
+// writeToFile creates the file and writes it with write, the file is closed either way
+func writeToFile(fileName string, write func(w io.Writer) error) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
 }
 
 func main() {
@@ -696,6 +488,91 @@ func main() {
 	imageName := "QRCode"
 	saveLocation := "C:\\Users\\marce\\Documents\\Git\\QRCodeGenerator\\" + imageName + ".png"
 
+	maskGallery := flag.String("mask-gallery", "", "write the eight masked variants of the code with their penalties to this PNG file")
+	heatmap := flag.Bool("heatmap", false, "tint the modules of the mask gallery by the penalty points they get")
+	logo := flag.String("logo", "", "draw the PNG or JPEG image of this file in the center of the code, the version and error level are picked so the modules under it can be recovered")
+	logoRatio := flag.Float64("logo-ratio", 0.2, "width of the logo as a part of the code width")
+	halftone := flag.String("halftone", "", "draw the code over the PNG or JPEG image of this file as a halftone picture code")
+	halftonePixels := flag.Int("halftone-pixels", 2, "pixels per submodule of the halftone code")
+	mask := flag.Int("mask", -1, "use this mask pattern, from 0 to 7, instead of picking one")
+	maskStrategy := flag.String("mask-strategy", "penalty", "how the mask is picked: penalty (the one of the spec), fast (for bulk jobs) or picture")
+	maskPicture := flag.String("mask-picture", "", "PNG or JPEG file the picture mask strategy makes the code look like")
+	placement := flag.String("placement", "", "write the block, codeword and bit of every data module to this file, as SVG when it ends in .svg and JSON otherwise")
+	flag.Parse()
+
+	if *maskGallery != "" {
+		logger.Info("Generating mask gallery for data: ", stringToEncode)
+		if err := writeMaskGallery(*maskGallery, stringToEncode, *heatmap); err != nil {
+			logger.Error("Error generating the mask gallery, Error: ", err)
+			return
+		}
+		logger.Info("Finished generating mask gallery, saved in: ", *maskGallery)
+		return
+	}
+
+	if *placement != "" {
+		logger.Info("Generating placement map for data: ", stringToEncode)
+		err := writeToFile(*placement, func(w io.Writer) error {
+			return renderPlacementMap(w, stringToEncode, strings.HasSuffix(strings.ToLower(*placement), ".svg"))
+		})
+		if err != nil {
+			logger.Error("Error generating the placement map, Error: ", err)
+			return
+		}
+		logger.Info("Finished generating placement map, saved in: ", *placement)
+		return
+	}
+
+	if *logo != "" {
+		logger.Info("Generating QR code with logo for data: ", stringToEncode)
+		logoImage, err := readImage(*logo)
+		if err != nil {
+			logger.Error("Error reading the logo, Error: ", err)
+			return
+		}
+		err = writeToFile(saveLocation, func(w io.Writer) error {
+			return renderLogoQR(w, stringToEncode, logoImage, *logoRatio)
+		})
+		if err != nil {
+			logger.Error("Error generating QR code with logo, Error: ", err)
+			return
+		}
+		logger.Info("Finished generating QR code, saved in: ", saveLocation)
+		return
+	}
+
+	if *halftone != "" {
+		logger.Info("Generating halftone QR code for data: ", stringToEncode)
+		background, err := readImage(*halftone)
+		if err != nil {
+			logger.Error("Error reading the halftone background, Error: ", err)
+			return
+		}
+		err = writeToFile(saveLocation, func(w io.Writer) error {
+			return renderHalftoneQR(w, stringToEncode, background, *halftonePixels)
+		})
+		if err != nil {
+			logger.Error("Error generating halftone QR code, Error: ", err)
+			return
+		}
+		logger.Info("Finished generating QR code, saved in: ", saveLocation)
+		return
+	}
+
+	var picture image.Image
+	if *maskPicture != "" {
+		var err error
+		if picture, err = readImage(*maskPicture); err != nil {
+			logger.Error("Error reading the mask picture, Error: ", err)
+			return
+		}
+	}
+	maskSelector, err := getMaskSelector(*maskStrategy, *mask, picture)
+	if err != nil {
+		logger.Error("Error choosing the mask selector, Error: ", err)
+		return
+	}
+
 	logger.Info("Generating QR code for data: ", stringToEncode)
 	QRversion, err := getQRInfoByData(stringToEncode)
 	if err != nil {
@@ -704,7 +581,11 @@ func main() {
 	}
 	logger.Info("Using Version: ", QRversion.Version, ", Size: ", QRversion.Size, ", Error Correction: ", QRversion.ErrorLevel, ", Encoding Mode: ", QRversion.EncodingMode)
 
-	QRArray := generateQR(QRversion, QR_CODE_STEP_MASK)
+	QRArray, err := generateQRWithMaskSelector(&QRversion, QR_CODE_STEP_MASK, maskSelector)
+	if err != nil {
+		logger.Error("Error encoding data, Error: ", err)
+		return
+	}
 	logger.Info("Finished encoding data")
 
 	logger.Info("Generating Img")
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/generator"
+	"QRCodeGenerator/utils"
+	"slices"
+	"testing"
+)
+
+// getTestQRInfo returns the info of every supported version and error level
+func getTestQRInfo(t *testing.T) []QRCodeInfo {
+	t.Helper()
+	var infos []QRCodeInfo
+	for version := 1; version <= generator.MAX_SUPPORTED_VERSION; version++ {
+		for _, errorLevel := range generator.GetErrorLevels() {
+			info, ok := getQRInfoByVersion("A", version, errorLevel)
+			if !ok {
+				t.Fatalf("no info for version %d-%s", version, errorLevel.Letter())
+			}
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+func TestTemplateRoles(t *testing.T) {
+	for _, info := range getTestQRInfo(t) {
+		QRTemplate, roles := drawer.GetQRTemplate(info)
+		sizeRoles, err := drawer.GetModuleRoles(info.Size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.EqualFunc(roles, sizeRoles, slices.Equal) {
+			t.Errorf("version %d-%s: the roles of the size do not match the template", info.Version, info.ErrorLevel.Letter())
+		}
+		counts := make(map[drawer.ModuleRole]int)
+		for i := range QRTemplate {
+			for j := range QRTemplate[i] {
+				// every module the template draws has the role of its pattern, the rest are data
+				if (QRTemplate[i][j] == 0) != (roles[i][j] == drawer.ModuleRole_Data) {
+					t.Fatalf("version %d-%s: module (%d, %d) is %d with role %d", info.Version, info.ErrorLevel.Letter(), i, j, QRTemplate[i][j], roles[i][j])
+				}
+				counts[roles[i][j]]++
+			}
+		}
+
+		alignments, timingAlignments := 0, 0
+		if n := len(info.AlignSquareCordenates); n > 0 {
+			// the three next to the position squares are not drawn, the ones on the
+			// timing lines cut 5 modules of them
+			alignments = n*n - 3
+			timingAlignments = 2 * (n - 2)
+		}
+		versionModules := 0
+		if info.Version >= 7 {
+			versionModules = 36
+		}
+		want := map[drawer.ModuleRole]int{
+			drawer.ModuleRole_FinderOuter: 3 * 24,
+			drawer.ModuleRole_FinderGap:   3 * 16,
+			drawer.ModuleRole_FinderInner: 3 * 9,
+			drawer.ModuleRole_Separator:   3 * 15,
+			drawer.ModuleRole_Alignment:   alignments * 25,
+			drawer.ModuleRole_Timing:      2*(info.Size-16) - 5*timingAlignments,
+			drawer.ModuleRole_Format:      30,
+			drawer.ModuleRole_Version:     versionModules,
+			drawer.ModuleRole_DarkModule:  1,
+		}
+		for role, count := range want {
+			if counts[role] != count {
+				t.Errorf("version %d-%s: %d modules with role %d, want %d", info.Version, info.ErrorLevel.Letter(), counts[role], role, count)
+			}
+		}
+	}
+}
+
+// codewordBits returns the bits of the codewords, most significant bit first
+func codewordBits(codewords []int) []bool {
+	bits := make([]bool, 0, len(codewords)*8)
+	for _, codeword := range codewords {
+		bits = append(bits, utils.ByteToBoolArray(byte(codeword))...)
+	}
+	return bits
+}
+
+func TestStructuredFinalMessage(t *testing.T) {
+	// 5-Q has 2 blocks of 15 data codewords and 2 of 16, with 18 error correction codewords each
+	info, ok := getQRInfoByVersion("A", 5, generator.ErrorLevel_Q)
+	if !ok {
+		t.Fatal("no info for version 5-Q")
+	}
+	blockLengths := []int{15, 15, 16, 16}
+
+	var data []int
+	var errorCorrection [][]bool
+	var blocks [][]int
+	for block, length := range blockLengths {
+		var blockData []int
+		for range length {
+			blockData = append(blockData, len(data))
+			data = append(data, len(data))
+		}
+		blocks = append(blocks, blockData)
+		var blockEC []int
+		for k := range info.CodeWords.ECCWPerBlock {
+			blockEC = append(blockEC, 100+block*info.CodeWords.ECCWPerBlock+k)
+		}
+		errorCorrection = append(errorCorrection, codewordBits(blockEC))
+	}
+
+	// the data codewords are taken one of every block in turn, the longer blocks end with
+	// their last one, then the error correction codewords the same way
+	var want []int
+	for k := range blockLengths[len(blockLengths)-1] {
+		for _, blockData := range blocks {
+			if k < len(blockData) {
+				want = append(want, blockData[k])
+			}
+		}
+	}
+	for k := range info.CodeWords.ECCWPerBlock {
+		for block := range blockLengths {
+			want = append(want, 100+block*info.CodeWords.ECCWPerBlock+k)
+		}
+	}
+
+	got := utils.BoolArrayToByte(getStructuredFinalMessage(info, codewordBits(data), errorCorrection))
+	if len(got) != len(want) {
+		t.Fatalf("got %d codewords, want %d", len(got), len(want))
+	}
+	for k := range want {
+		if int(got[k]) != want[k] {
+			t.Fatalf("codeword %d is %d, want %d", k, got[k], want[k])
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/generator"
+	"QRCodeGenerator/maskscore"
+	"sync"
+)
+
+// maskOverlays is what every mask does to a symbol of one version and error level:
+// the masked symbol is (unmasked & kept) ^ flipped[mask]. kept clears the format
+// information and flipped has the modules the mask inverts plus the dark format modules.
+type maskOverlays struct {
+	kept    maskscore.Bitset
+	flipped []maskscore.Bitset // in the order of generator.GetMaskPatterns
+}
+
+type maskOverlaysKey struct {
+	version    int
+	errorLevel ErrorLevel
+}
+
+// maskOverlaysCache has the overlays of every version and error level used so far, they
+// are made from the template of generateQRTemplate which only depends on the version
+var maskOverlaysCache sync.Map
+
+// maskBufferPool keeps the lines of the bitsets used by getBitsetMaskPenalties so
+// evaluating many codes does not allocate them again
+var maskBufferPool = sync.Pool{New: func() any { return new([]uint64) }}
+
+func getMaskOverlays(QRVersionInfo QRCodeInfo, QRTemplate [][]uint8) *maskOverlays {
+	key := maskOverlaysKey{QRVersionInfo.Version, QRVersionInfo.ErrorLevel}
+	if overlays, ok := maskOverlaysCache.Load(key); ok {
+		return overlays.(*maskOverlays)
+	}
+
+	size := QRVersionInfo.Size
+	maskPaterns := generator.GetMaskPatterns()
+	overlays := &maskOverlays{kept: maskscore.NewBitset(size), flipped: make([]maskscore.Bitset, len(maskPaterns))}
+	// AddFormatVersion only writes the format modules, the cells it leaves at 0 are kept
+	formatInformation := make([][]uint8, size)
+	for i := range formatInformation {
+		formatInformation[i] = make([]uint8, size)
+	}
+	for maskIndex, mask := range maskPaterns {
+		drawer.AddFormatVersion(formatInformation, nil, QRVersionInfo.ErrorLevel, mask, size)
+		maskFunction := generator.MaskFunctions[mask]
+		overlays.flipped[maskIndex] = maskscore.NewBitset(size)
+		for i := range size {
+			for j := range size {
+				if QRTemplate[i][j] == 0 {
+					overlays.flipped[maskIndex].Set(i, j, maskFunction(i, j))
+				} else {
+					overlays.flipped[maskIndex].Set(i, j, formatInformation[i][j] == drawer.BLACK_COLOR)
+				}
+			}
+		}
+	}
+	for i := range size {
+		for j := range size {
+			overlays.kept.Set(i, j, formatInformation[i][j] == 0)
+		}
+	}
+
+	actual, _ := maskOverlaysCache.LoadOrStore(key, overlays)
+	return actual.(*maskOverlays)
+}
+
+// bitsetAt returns the bitset number index of a buffer of lines
+func bitsetAt(lines []uint64, index int, size int) maskscore.Bitset {
+	words := maskscore.LineWords(size)
+	start := index * 2 * size * words
+	middle, end := start+size*words, start+2*size*words
+	return maskscore.Bitset{Size: size, Words: words, Rows: lines[start:middle:middle], Columns: lines[middle:end:end]}
+}
+
+// getBitsetMaskPenalties gives the same penalties as getMaskPenalties, without heatmaps
+func getBitsetMaskPenalties(QRVersionInfo QRCodeInfo, QRTemplate [][]uint8, QRFinal [][]uint8) []MaskPenalty {
+	return getBitsetMaskScores(QRVersionInfo, QRTemplate, QRFinal, maskscore.Score)
+}
+
+// getBitsetMaskScores scores the symbol of every mask with score, in the order of
+// generator.GetMaskPatterns. The unmasked symbol is turned into a bitset once and every
+// mask is evaluated by combining it with the cached overlays, so no matrix is copied.
+// The masks are scored one after the other, starting goroutines costs as much as
+// scoring a small symbol, so batch jobs should run a code per goroutine instead.
+func getBitsetMaskScores(QRVersionInfo QRCodeInfo, QRTemplate [][]uint8, QRFinal [][]uint8, score func(maskscore.Bitset) maskscore.Penalty) []MaskPenalty {
+	size := len(QRFinal)
+	overlays := getMaskOverlays(QRVersionInfo, QRTemplate)
+
+	buffer := maskBufferPool.Get().(*[]uint64)
+	defer maskBufferPool.Put(buffer)
+	// the unmasked symbol and then one bitset per mask
+	if needed := (len(overlays.flipped) + 1) * 2 * size * maskscore.LineWords(size); cap(*buffer) < needed {
+		*buffer = make([]uint64, needed)
+	} else {
+		*buffer = (*buffer)[:needed]
+		clear(*buffer)
+	}
+	unmasked := bitsetAt(*buffer, 0, size)
+	for i := range size {
+		for j := range size {
+			if QRFinal[i][j] == drawer.BLACK_COLOR {
+				unmasked.Set(i, j, true)
+			}
+		}
+	}
+
+	maskPaterns := generator.GetMaskPatterns()
+	penalties := make([]MaskPenalty, len(maskPaterns))
+	for maskIndex, mask := range maskPaterns {
+		masked := bitsetAt(*buffer, maskIndex+1, size)
+		flipped := overlays.flipped[maskIndex]
+		for word := range masked.Rows {
+			masked.Rows[word] = unmasked.Rows[word]&overlays.kept.Rows[word] ^ flipped.Rows[word]
+			masked.Columns[word] = unmasked.Columns[word]&overlays.kept.Columns[word] ^ flipped.Columns[word]
+		}
+		penalties[maskIndex] = MaskPenalty{Mask: mask, Penalty: score(masked)}
+	}
+	return penalties
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/utils"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// randomData returns the unmasked symbol of info with random data modules
+func randomData(info QRCodeInfo, QRTemplate [][]uint8, random *rand.Rand) [][]uint8 {
+	QRFinal := generateQR(&info, QR_CODE_STEP_REMINDER_BITS)
+	for i := range QRFinal {
+		for j := range QRFinal[i] {
+			if QRTemplate[i][j] != 0 {
+				continue
+			}
+			QRFinal[i][j] = drawer.WHITE_COLOR
+			if random.Intn(2) == 0 {
+				QRFinal[i][j] = drawer.BLACK_COLOR
+			}
+		}
+	}
+	return QRFinal
+}
+
+func TestBitsetMaskPenalties(t *testing.T) {
+	random := rand.New(rand.NewSource(50))
+	for _, info := range getTestQRInfo(t) {
+		QRTemplate := generateQRTemplate(info)
+		for range 5 {
+			QRFinal := randomData(info, QRTemplate, random)
+			QRFinalCopy := utils.DeepCopy2D(QRFinal)
+			want, _ := getMaskPenalties(info, QRTemplate, QRFinal)
+			got := getBitsetMaskPenalties(info, QRTemplate, QRFinal)
+			if len(got) != len(want) {
+				t.Fatalf("version %d-%s: got %d penalties, want %d", info.Version, info.ErrorLevel.Letter(), len(got), len(want))
+			}
+			for index := range want {
+				gotRules := [4]uint{got[index].N1, got[index].N2, got[index].N3, got[index].N4}
+				wantRules := [4]uint{want[index].N1, want[index].N2, want[index].N3, want[index].N4}
+				if got[index].Mask != want[index].Mask || gotRules != wantRules {
+					t.Fatalf("version %d-%s: mask %d has N1-N4 %v, mask %d of getMaskPenalties %v", info.Version, info.ErrorLevel.Letter(), got[index].Mask, gotRules, want[index].Mask, wantRules)
+				}
+			}
+			if !sameSymbol(QRFinal, QRFinalCopy) {
+				t.Fatalf("version %d-%s: the unmasked symbol was changed", info.Version, info.ErrorLevel.Letter())
+			}
+		}
+	}
+}
+
+// maskPenaltiesBenchmarkData needs versions 1-Q, 2-M and 6-L
+var maskPenaltiesBenchmarkData = []string{"HELLO WORLD", "https://example.com/a", "https://example.com/batch/labels?customer=0042&order=2024-0001&item=17&lot=A7F3&serial=000000123456789&batch=2024-10-19"}
+
+func benchmarkMaskPenalties(b *testing.B, getPenalties func(QRCodeInfo, [][]uint8, [][]uint8) []MaskPenalty) {
+	for _, data := range maskPenaltiesBenchmarkData {
+		info, QRTemplate, QRFinal := getTestSymbol(b, data)
+		b.Run(strconv.Itoa(info.Version)+"-"+info.ErrorLevel.Letter(), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				getPenalties(info, QRTemplate, QRFinal)
+			}
+		})
+	}
+}
+
+// BenchmarkMaskPenaltiesCopies is the evaluation before the bitsets, a copy of the matrix
+// for every mask scanned one after another
+func BenchmarkMaskPenaltiesCopies(b *testing.B) {
+	benchmarkMaskPenalties(b, func(info QRCodeInfo, QRTemplate [][]uint8, QRFinal [][]uint8) []MaskPenalty {
+		penalties, _ := getMaskPenalties(info, QRTemplate, QRFinal)
+		return penalties
+	})
+}
+
+func BenchmarkMaskPenaltiesBitset(b *testing.B) {
+	benchmarkMaskPenalties(b, getBitsetMaskPenalties)
+}
+
+// BenchmarkMaskPenaltiesBitsetCodes scores a code per goroutine, the way batch jobs use
+// getBitsetMaskPenalties since it scores the masks of a code one after the other
+func BenchmarkMaskPenaltiesBitsetCodes(b *testing.B) {
+	for _, data := range maskPenaltiesBenchmarkData {
+		info, QRTemplate, QRFinal := getTestSymbol(b, data)
+		b.Run(strconv.Itoa(info.Version)+"-"+info.ErrorLevel.Letter(), func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					getBitsetMaskPenalties(info, QRTemplate, QRFinal)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/generator"
+	"QRCodeGenerator/maskscore"
+	"QRCodeGenerator/utils"
+	"io"
+	"os"
+	"strconv"
+)
+
+// MaskPenalty has the points of every penalty rule for a mask, see maskscore.Penalty
+type MaskPenalty struct {
+	Mask MaskPattern
+	maskscore.Penalty
+}
+
+// getMaskedSymbol returns the symbol with the mask and its format information applied
+func getMaskedSymbol(QRVersionInfo QRCodeInfo, mask MaskPattern, QRTemplate [][]uint8, QRFinal [][]uint8) [][]uint8 {
+	QRFinalCopy := utils.DeepCopy2D(QRFinal)
+	//overwrite the temporal mask infomration
+	drawer.AddFormatVersion(QRFinalCopy, nil, QRVersionInfo.ErrorLevel, mask, QRVersionInfo.Size)
+	return applyMask(mask, QRVersionInfo.ErrorLevel, QRTemplate, QRFinalCopy)
+}
+
+// getMaskPenalties returns the penalty of every mask and the masked symbols, in the order of generator.GetMaskPatterns
+func getMaskPenalties(QRVersionInfo QRCodeInfo, QRTemplate [][]uint8, QRFinal [][]uint8) ([]MaskPenalty, [][][]uint8) {
+	maskPaterns := generator.GetMaskPatterns()
+	penalties := make([]MaskPenalty, len(maskPaterns))
+	symbols := make([][][]uint8, len(maskPaterns))
+	for maskIndex, mask := range maskPaterns {
+		symbols[maskIndex] = getMaskedSymbol(QRVersionInfo, mask, QRTemplate, QRFinal)
+		penalties[maskIndex] = MaskPenalty{Mask: mask, Penalty: maskscore.Evaluate(symbols[maskIndex])}
+	}
+	return penalties, symbols
+}
+
+// renderMaskGallery writes the eight masked variants of the code side by side with their
+// penalties, the one getBestMaskPattern picks is marked. With heatmap the modules are
+// tinted by the penalty points they get.
+func renderMaskGallery(w io.Writer, stringToEncode string, heatmap bool) error {
+	QRversion, err := getQRInfoByData(stringToEncode)
+	if err != nil {
+		return err
+	}
+	QRTemplate := generateQRTemplate(QRversion)
+	QRArrayWithData := generateQR(&QRversion, QR_CODE_STEP_REMINDER_BITS)
+	bestMask := getBestMaskPattern(QRversion, QRTemplate, QRArrayWithData)
+	penalties, symbols := getMaskPenalties(QRversion, QRTemplate, QRArrayWithData)
+
+	entries := make([]drawer.GalleryEntry, len(penalties))
+	for index, penalty := range penalties {
+		title := "MASK " + strconv.Itoa(int(penalty.Mask))
+		if penalty.Mask == bestMask {
+			title += " (BEST)"
+		}
+		entries[index] = drawer.GalleryEntry{
+			QRArray: symbols[index],
+			Lines: []string{
+				title,
+				"N1 " + strconv.Itoa(int(penalty.N1)) + " N2 " + strconv.Itoa(int(penalty.N2)),
+				"N3 " + strconv.Itoa(int(penalty.N3)) + " N4 " + strconv.Itoa(int(penalty.N4)),
+				"TOTAL " + strconv.Itoa(int(penalty.Total())),
+			},
+		}
+		if heatmap {
+			entries[index].Heatmap = penalty.Heatmap
+		}
+	}
+	return drawer.RenderGalleryPNG(w, entries, drawer.GalleryOptions{Scale: 6, QuietZone: 4, Columns: 4})
+}
+
+// writeMaskGallery saves the gallery of renderMaskGallery in a PNG file
+func writeMaskGallery(fileName string, stringToEncode string, heatmap bool) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	if err := renderMaskGallery(file, stringToEncode, heatmap); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
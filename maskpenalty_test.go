@@ -0,0 +1,104 @@
+package main
+
+import (
+	"QRCodeGenerator/generator"
+	"QRCodeGenerator/utils"
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+)
+
+// maskPenaltyCases has the points of every rule for the eight masks, in the order of
+// generator.GetMaskPatterns, and the mask getBestMaskPattern picks
+var maskPenaltyCases = []struct {
+	data       string
+	version    int
+	errorLevel ErrorLevel
+	penalties  [8][4]uint
+	bestMask   MaskPattern
+}{
+	{"01234567", 1, generator.ErrorLevel_M, [8][4]uint{
+		{155, 102, 800, 0}, {180, 153, 760, 0}, {206, 111, 720, 0}, {187, 105, 760, 0},
+		{171, 120, 880, 0}, {220, 177, 800, 0}, {191, 108, 800, 0}, {170, 165, 720, 0},
+	}, 2},
+	{"HELLO WORLD", 1, generator.ErrorLevel_Q, [8][4]uint{
+		{177, 90, 760, 0}, {172, 138, 800, 0}, {205, 141, 800, 0}, {177, 144, 760, 0},
+		{173, 99, 840, 0}, {191, 165, 760, 0}, {172, 102, 800, 0}, {189, 117, 760, 0},
+	}, 0},
+}
+
+func TestMaskPenalties(t *testing.T) {
+	for _, test := range maskPenaltyCases {
+		info, ok := getQRInfoByVersion(test.data, test.version, test.errorLevel)
+		if !ok {
+			t.Fatalf("%q does not fit in version %d-%s", test.data, test.version, test.errorLevel.Letter())
+		}
+		QRTemplate := generateQRTemplate(info)
+		QRArrayWithData := generateQR(&info, QR_CODE_STEP_REMINDER_BITS)
+		penalties, symbols := getMaskPenalties(info, QRTemplate, QRArrayWithData)
+		for index, penalty := range penalties {
+			got := [4]uint{penalty.N1, penalty.N2, penalty.N3, penalty.N4}
+			if penalty.Mask != MaskPattern(index) || got != test.penalties[index] {
+				t.Errorf("%q mask %d: got N1-N4 %v, want %v", test.data, penalty.Mask, got, test.penalties[index])
+			}
+
+			// rule 4 looks at the whole symbol, the other rules are spread over the modules
+			heat := 0.0
+			for i := range penalty.Heatmap {
+				for j := range penalty.Heatmap[i] {
+					heat += penalty.Heatmap[i][j]
+				}
+			}
+			if rules := float64(penalty.N1 + penalty.N2 + penalty.N3); math.Abs(heat-rules) > 1e-6 {
+				t.Errorf("%q mask %d: the heatmap adds up to %g, N1 to N3 to %g", test.data, penalty.Mask, heat, rules)
+			}
+			if len(symbols[index]) != info.Size {
+				t.Errorf("%q mask %d: symbol of %d modules, want %d", test.data, penalty.Mask, len(symbols[index]), info.Size)
+			}
+		}
+		if bestMask := getBestMaskPattern(info, QRTemplate, QRArrayWithData); bestMask != test.bestMask {
+			t.Errorf("%q: got best mask %d, want %d", test.data, bestMask, test.bestMask)
+		}
+	}
+}
+
+// TestAnnexCodewords checks the encoding example of the annex of ISO/IEC 18004, the same
+// codewords are placed and scored in the maskscore tests
+func TestAnnexCodewords(t *testing.T) {
+	info, ok := getQRInfoByVersion("01234567", 1, generator.ErrorLevel_M)
+	if !ok {
+		t.Fatal("no info for version 1-M")
+	}
+	dataBits := getString_Encoded(info)
+	dataBits = append(getCharacterCount_Binary(info), dataBits...)
+	dataBits = append(getEncodeMode_Binary(info), dataBits...)
+	dataBits = append(dataBits, getPadingBits_Binary(info, len(dataBits))...)
+	finalMessage := getStructuredFinalMessage(info, dataBits, getCodeWords_Encoded(info, dataBits))
+
+	want := []byte{
+		16, 32, 12, 86, 97, 128, 236, 17, 236, 17, 236, 17, 236, 17, 236, 17,
+		165, 36, 212, 193, 237, 54, 199, 135, 44, 85,
+	}
+	if got := utils.BoolArrayToByte(finalMessage); !bytes.Equal(got, want) {
+		t.Errorf("got codewords %v, want %v", got, want)
+	}
+}
+
+func TestRenderMaskGallery(t *testing.T) {
+	for _, heatmap := range []bool{false, true} {
+		var buffer bytes.Buffer
+		if err := renderMaskGallery(&buffer, "HELLO WORLD", heatmap); err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(&buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// two rows of four codes, every one with its quiet zone
+		side := (21 + 2*4) * 6
+		if bounds := img.Bounds(); bounds.Dx() < 4*side || bounds.Dy() < 2*side {
+			t.Errorf("heatmap %v: gallery of %dx%d is too small for 8 codes of %d pixels", heatmap, bounds.Dx(), bounds.Dy(), side)
+		}
+	}
+}
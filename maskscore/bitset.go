@@ -0,0 +1,169 @@
+package maskscore
+
+import (
+	"math/bits"
+)
+
+// Bitset is a symbol with one bit per module, set on dark modules. Every line takes Words
+// uint64 and bit k%64 of word k/64 of a line is its module k. Row i is the module (i, k)
+// and column j the module (k, j), both views are kept so every rule works on whole lines.
+type Bitset struct {
+	Size    int
+	Words   int      // uint64 per line
+	Rows    []uint64 // row i is Rows[i*Words : (i+1)*Words]
+	Columns []uint64
+}
+
+// LineWords is the number of uint64 a line of a symbol of the size takes
+func LineWords(size int) int {
+	return (size + 63) / 64
+}
+
+// NewBitset returns an all light symbol
+func NewBitset(size int) Bitset {
+	words := LineWords(size)
+	lines := make([]uint64, 2*size*words)
+	return Bitset{Size: size, Words: words, Rows: lines[: size*words : size*words], Columns: lines[size*words:]}
+}
+
+func (bitset Bitset) Set(i int, j int, dark bool) {
+	row := &bitset.Rows[i*bitset.Words+j/64]
+	column := &bitset.Columns[j*bitset.Words+i/64]
+	if dark {
+		*row |= 1 << (j % 64)
+		*column |= 1 << (i % 64)
+	} else {
+		*row &^= 1 << (j % 64)
+		*column &^= 1 << (i % 64)
+	}
+}
+
+func (bitset Bitset) Dark(i int, j int) bool {
+	return bitset.Rows[i*bitset.Words+j/64]>>(j%64)&1 == 1
+}
+
+func (bitset Bitset) Row(i int) []uint64 {
+	return bitset.Rows[i*bitset.Words : (i+1)*bitset.Words]
+}
+
+func (bitset Bitset) Column(j int) []uint64 {
+	return bitset.Columns[j*bitset.Words : (j+1)*bitset.Words]
+}
+
+// Score gives the same points as Evaluate without the heatmap, it does not allocate
+func Score(symbol Bitset) Penalty {
+	size := symbol.Size
+	penalty := ScoreBlocks(symbol)
+	for line := range size {
+		penalty.N1 += bitRuns(symbol.Row(line), size) + bitRuns(symbol.Column(line), size)
+		penalty.N3 += bitPatterns(symbol.Row(line), size) + bitPatterns(symbol.Column(line), size)
+	}
+	return penalty
+}
+
+// ScoreBlocks only gives the points of rules 2 and 4, the 2x2 blocks and the balance of
+// dark modules, N1 and N3 are left at 0. It only reads the rows so Columns can be nil.
+func ScoreBlocks(symbol Bitset) Penalty {
+	size := symbol.Size
+	var penalty Penalty
+	darkModules := 0
+	for _, word := range symbol.Rows {
+		darkModules += bits.OnesCount64(word)
+	}
+	for line := range size - 1 {
+		penalty.N2 += bitBlocks(symbol.Row(line), symbol.Row(line+1), size)
+	}
+	penalty.N4 = balancePoints(darkModules, size*size)
+	return penalty
+}
+
+// bitsFrom returns the 64 modules of the line starting at module from, from can be
+// negative. Modules outside of the words of the line are 0, so they are light.
+func bitsFrom(line []uint64, from int) uint64 {
+	word, shift := from>>6, uint(from&63)
+	var result uint64
+	if word >= 0 && word < len(line) {
+		result = line[word] >> shift
+	}
+	if shift != 0 && word+1 >= 0 && word+1 < len(line) {
+		result |= line[word+1] << (64 - shift)
+	}
+	return result
+}
+
+// lowBits returns a word with its n lowest bits set, n can be negative or over 64
+func lowBits(n int) uint64 {
+	if n >= 64 {
+		return ^uint64(0)
+	}
+	if n <= 0 {
+		return 0
+	}
+	return 1<<n - 1
+}
+
+// bitRuns is lineRuns on a line of bits, the runs start where a module differs from
+// the one before it so only those modules are visited
+func bitRuns(line []uint64, size int) uint {
+	var points uint
+	addRun := func(length int) {
+		if length >= minRunLength {
+			points += uint(PENALTY_N1 + length - minRunLength)
+		}
+	}
+	runStart := 0
+	var previous uint64 // the last module of the word before, shifted in as bit 0
+	for word, modules := range line {
+		// a dark first module is seen as a start too, it only adds a run of length 0
+		starts := (modules ^ (modules<<1 | previous)) & lowBits(size-word*64)
+		previous = modules >> 63
+		for starts != 0 {
+			start := word*64 + bits.TrailingZeros64(starts)
+			addRun(start - runStart)
+			runStart = start
+			starts &= starts - 1
+		}
+	}
+	addRun(size - runStart)
+	return points
+}
+
+// bitBlocks counts the 2x2 blocks of the same color between two neighbour rows, bit k
+// of a word of blocks is the block with its top left module at column k
+func bitBlocks(row []uint64, next []uint64, size int) uint {
+	var blocks int
+	for word := range row {
+		from := word * 64
+		rowRight, nextRight := bitsFrom(row, from+1), bitsFrom(next, from+1)
+		sameColumn := ^(row[word] ^ next[word]) & ^(rowRight ^ nextRight)
+		sameRow := ^(row[word] ^ rowRight)
+		blocks += bits.OnesCount64(sameColumn & sameRow & lowBits(size-1-from))
+	}
+	return uint(blocks) * PENALTY_N2
+}
+
+// bitPatterns is linePatterns on a line of bits, bit k of the masks says if the pattern
+// starts at module k of the word. Modules outside of the symbol are 0 so they are light.
+func bitPatterns(line []uint64, size int) uint {
+	var patterns int
+	for from := 0; from <= size-len(finderLike); from += 64 {
+		starts := lowBits(size - len(finderLike) + 1 - from)
+		for k, patternDark := range finderLike {
+			if patternDark {
+				starts &= bitsFrom(line, from+k)
+			} else {
+				starts &= ^bitsFrom(line, from+k)
+			}
+		}
+		if starts == 0 {
+			continue
+		}
+		lightBefore, lightAfter := ^uint64(0), ^uint64(0)
+		for k := 1; k <= 4; k++ {
+			lightBefore &= ^bitsFrom(line, from-k)
+			lightAfter &= ^bitsFrom(line, from+len(finderLike)+k-1)
+		}
+		patterns += bits.OnesCount64(starts & (lightBefore | lightAfter))
+	}
+	return uint(patterns) * PENALTY_N3
+}
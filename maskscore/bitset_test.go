@@ -0,0 +1,125 @@
+package maskscore
+
+import (
+	"QRCodeGenerator/drawer"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func bitsetFromSymbol(symbol [][]uint8) Bitset {
+	bitset := NewBitset(len(symbol))
+	for i := range symbol {
+		for j := range symbol[i] {
+			bitset.Set(i, j, symbol[i][j] == drawer.BLACK_COLOR)
+		}
+	}
+	return bitset
+}
+
+// edgeSymbol returns a light symbol with line written in row i from column j, lines
+// that start at a negative column or end after the symbol are cut
+func edgeSymbol(size int, i int, j int, line string) []string {
+	rows := make([]string, size)
+	for k := range rows {
+		rows[k] = strings.Repeat(".", size)
+	}
+	row := []byte(rows[i])
+	for k := range line {
+		if j+k >= 0 && j+k < size {
+			row[j+k] = line[k]
+		}
+	}
+	rows[i] = string(row)
+	return rows
+}
+
+// randomSymbol returns a symbol where every module is dark with the probability
+func randomSymbol(size int, dark float64, random *rand.Rand) []string {
+	rows := make([]string, size)
+	for i := range rows {
+		var row strings.Builder
+		for range size {
+			if random.Float64() < dark {
+				row.WriteByte('#')
+			} else {
+				row.WriteByte('.')
+			}
+		}
+		rows[i] = row.String()
+	}
+	return rows
+}
+
+type scoreCase struct {
+	name string
+	rows []string
+}
+
+func getScoreCases() []scoreCase {
+	var cases []scoreCase
+	for _, test := range ruleCases {
+		cases = append(cases, scoreCase{test.name, test.rows})
+	}
+	// lines that end near a word boundary or take more than one word, up to version 40
+	for _, size := range []int{21, 45, 57, 59, 60, 63, 64, 65, 127, 128, 129, 177} {
+		for _, line := range []string{"#.###.#", "#.###.#....", "....#.###.#", "#####", strings.Repeat("#", size)} {
+			for _, j := range []int{0, size - len(line), size - len(line) + 1, -1, size / 2} {
+				for _, i := range []int{0, size - 1} {
+					rows := edgeSymbol(size, i, j, line)
+					name := fmt.Sprintf("size %d %q at (%d, %d)", size, line, i, j)
+					cases = append(cases, scoreCase{name, rows}, scoreCase{name + " transposed", transpose(rows)})
+				}
+			}
+		}
+		cases = append(cases, scoreCase{fmt.Sprintf("size %d all dark", size), darkFirst(size, size*size)})
+		cases = append(cases, scoreCase{fmt.Sprintf("size %d all light", size), darkFirst(size, 0)})
+	}
+
+	random := rand.New(rand.NewSource(48))
+	for size := 1; size <= 140; size++ {
+		for _, dark := range []float64{0.2, 0.5, 0.8} {
+			cases = append(cases, scoreCase{fmt.Sprintf("size %d random %g dark", size, dark), randomSymbol(size, dark, random)})
+		}
+	}
+	return cases
+}
+
+func TestScore(t *testing.T) {
+	for _, test := range getScoreCases() {
+		symbol := symbolFromRows(test.rows)
+		want := Evaluate(symbol)
+		got := Score(bitsetFromSymbol(symbol))
+		if got.N1 != want.N1 || got.N2 != want.N2 || got.N3 != want.N3 || got.N4 != want.N4 {
+			t.Errorf("%s: got N1-N4 %d %d %d %d, want %d %d %d %d", test.name, got.N1, got.N2, got.N3, got.N4, want.N1, want.N2, want.N3, want.N4)
+		}
+
+		bitset := bitsetFromSymbol(symbol)
+		blocks := ScoreBlocks(Bitset{Size: bitset.Size, Words: bitset.Words, Rows: bitset.Rows})
+		if blocks.N1 != 0 || blocks.N2 != want.N2 || blocks.N3 != 0 || blocks.N4 != want.N4 {
+			t.Errorf("%s: ScoreBlocks got N1-N4 %d %d %d %d, want 0 %d 0 %d", test.name, blocks.N1, blocks.N2, blocks.N3, blocks.N4, want.N2, want.N4)
+		}
+	}
+}
+
+// benchmarkSymbol is a random symbol the size of version 40
+func benchmarkSymbol() [][]uint8 {
+	return symbolFromRows(randomSymbol(177, 0.5, rand.New(rand.NewSource(40))))
+}
+
+func BenchmarkEvaluateVersion40(b *testing.B) {
+	symbol := benchmarkSymbol()
+	b.ResetTimer()
+	for range b.N {
+		Evaluate(symbol)
+	}
+}
+
+func BenchmarkScoreVersion40(b *testing.B) {
+	bitset := bitsetFromSymbol(benchmarkSymbol())
+	b.ResetTimer()
+	for range b.N {
+		Score(bitset)
+	}
+}
@@ -0,0 +1,146 @@
+// Package maskscore evaluates masked symbols with the penalty rules of ISO/IEC 18004
+// (section 7.8.3), the mask with the lowest total is the one the spec asks for.
+//
+// The spec encoding example, "01234567" in version 1-M, gets mask pattern 010 with
+// these rules, like in the annex of the standard.
+package maskscore
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/utils"
+)
+
+const (
+	PENALTY_N1 = 3  // runs of 5 modules of the same color, plus 1 for every extra module
+	PENALTY_N2 = 3  // every 2x2 block of the same color
+	PENALTY_N3 = 40 // every 1:1:3:1:1 finder like pattern with 4 light modules on one side
+	PENALTY_N4 = 10 // every 5% the dark modules are away from 50%
+
+	minRunLength = 5
+)
+
+// finderLike is the 1:1:3:1:1 dark:light:dark:light:dark pattern of rule 3
+var finderLike = []bool{true, false, true, true, true, false, true}
+
+// Penalty has the points of every rule, Heatmap has the points given to every module.
+// Rule 4 looks at the whole symbol so it is not in the heatmap.
+type Penalty struct {
+	N1      uint
+	N2      uint
+	N3      uint
+	N4      uint
+	Heatmap [][]float64
+}
+
+func (penalty Penalty) Total() uint {
+	return penalty.N1 + penalty.N2 + penalty.N3 + penalty.N4
+}
+
+// Evaluate scores a masked symbol, format and version information included
+func Evaluate(symbol [][]uint8) Penalty {
+	size := len(symbol)
+	penalty := Penalty{Heatmap: make([][]float64, size)}
+	for i := range penalty.Heatmap {
+		penalty.Heatmap[i] = make([]float64, size)
+	}
+
+	dark := func(i int, j int) bool {
+		return symbol[i][j] == drawer.BLACK_COLOR
+	}
+	for line := range size {
+		penalty.N1 += lineRuns(size, func(k int) bool { return dark(line, k) }, func(k int, heat float64) { penalty.Heatmap[line][k] += heat })
+		penalty.N1 += lineRuns(size, func(k int) bool { return dark(k, line) }, func(k int, heat float64) { penalty.Heatmap[k][line] += heat })
+		penalty.N3 += linePatterns(size, func(k int) bool { return dark(line, k) }, func(k int, heat float64) { penalty.Heatmap[line][k] += heat })
+		penalty.N3 += linePatterns(size, func(k int) bool { return dark(k, line) }, func(k int, heat float64) { penalty.Heatmap[k][line] += heat })
+	}
+
+	darkModules := 0
+	for i := range size {
+		for j := range size {
+			if dark(i, j) {
+				darkModules++
+			}
+			if i == size-1 || j == size-1 {
+				continue
+			}
+			if dark(i, j) == dark(i+1, j) && dark(i, j) == dark(i, j+1) && dark(i, j) == dark(i+1, j+1) {
+				penalty.N2 += PENALTY_N2
+				for _, module := range [4][2]int{{i, j}, {i + 1, j}, {i, j + 1}, {i + 1, j + 1}} {
+					penalty.Heatmap[module[0]][module[1]] += PENALTY_N2 / 4.0
+				}
+			}
+		}
+	}
+
+	penalty.N4 = balancePoints(darkModules, size*size)
+	return penalty
+}
+
+// balancePoints is rule 4 for a symbol of total modules
+func balancePoints(darkModules int, total int) uint {
+	if total == 0 {
+		return 0
+	}
+	// k is how many whole steps of 5% the dark modules are away from 50%
+	k := utils.Abs(darkModules*20-total*10) / total
+	return uint(k) * PENALTY_N4
+}
+
+// lineRuns scores the runs of a row or column, the run counter starts again on every line
+func lineRuns(size int, dark func(int) bool, heat func(int, float64)) uint {
+	var points uint
+	runStart := 0
+	for k := 1; k <= size; k++ {
+		if k < size && dark(k) == dark(runStart) {
+			continue
+		}
+		if length := k - runStart; length >= minRunLength {
+			runPoints := uint(PENALTY_N1 + length - minRunLength)
+			points += runPoints
+			for module := runStart; module < k; module++ {
+				heat(module, float64(runPoints)/float64(length))
+			}
+		}
+		runStart = k
+	}
+	return points
+}
+
+// linePatterns scores the finder like patterns of a row or column. Modules out of the
+// symbol are light since the quiet zone surrounds it, so a pattern next to the edge
+// has its 4 light modules there.
+func linePatterns(size int, dark func(int) bool, heat func(int, float64)) uint {
+	isLight := func(k int) bool {
+		return k < 0 || k >= size || !dark(k)
+	}
+	lightRun := func(from int, to int) bool {
+		for k := from; k < to; k++ {
+			if !isLight(k) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var points uint
+	for start := 0; start+len(finderLike) <= size; start++ {
+		matches := true
+		for k, patternDark := range finderLike {
+			if dark(start+k) != patternDark {
+				matches = false
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+		end := start + len(finderLike)
+		if lightRun(start-4, start) || lightRun(end, end+4) {
+			points += PENALTY_N3
+			for k := start; k < end; k++ {
+				heat(k, float64(PENALTY_N3)/float64(len(finderLike)))
+			}
+		}
+	}
+	return points
+}
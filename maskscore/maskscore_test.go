@@ -0,0 +1,251 @@
+package maskscore
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/utils"
+	"strings"
+	"testing"
+)
+
+// symbolFromRows turns rows of '#' (dark) and '.' (light) into a symbol
+func symbolFromRows(rows []string) [][]uint8 {
+	symbol := make([][]uint8, len(rows))
+	for i, row := range rows {
+		symbol[i] = make([]uint8, len(row))
+		for j, module := range row {
+			symbol[i][j] = drawer.WHITE_COLOR
+			if module == '#' {
+				symbol[i][j] = drawer.BLACK_COLOR
+			}
+		}
+	}
+	return symbol
+}
+
+// transpose turns the rows of a fixture into its columns
+func transpose(rows []string) []string {
+	columns := make([]string, len(rows[0]))
+	for j := range columns {
+		var column strings.Builder
+		for i := range rows {
+			column.WriteByte(rows[i][j])
+		}
+		columns[j] = column.String()
+	}
+	return columns
+}
+
+// darkFirst returns a symbol of the size with its first dark modules dark, row by row
+func darkFirst(size int, dark int) []string {
+	modules := strings.Repeat("#", dark) + strings.Repeat(".", size*size-dark)
+	rows := make([]string, size)
+	for i := range rows {
+		rows[i] = modules[i*size : (i+1)*size]
+	}
+	return rows
+}
+
+var checker = []string{
+	".#.#.#.#",
+	"#.#.#.#.",
+	".#.#.#.#",
+	"#.#.#.#.",
+	".#.#.#.#",
+	"#.#.#.#.",
+	".#.#.#.#",
+	"#.#.#.#.",
+}
+
+// withRows replaces the first rows of the checker board, that has no points of rules 1 to 3
+func withRows(rows ...string) []string {
+	return append(rows, checker[len(rows):]...)
+}
+
+// lineSymbol returns a square symbol with the line as first row and the other rows light
+func lineSymbol(line string) []string {
+	rows := []string{line}
+	for len(rows) < len(line) {
+		rows = append(rows, strings.Repeat(".", len(line)))
+	}
+	return rows
+}
+
+// ruleCases has small symbols that score on a single rule, rule is the one checked
+var ruleCases = []struct {
+	name string
+	rows []string
+	rule int
+	want uint
+}{
+	{"N1 run of 5", withRows("#####.#."), 1, PENALTY_N1},
+	{"N1 run of 7", withRows(".#######"), 1, PENALTY_N1 + 2},
+	{"N1 run in a column", transpose(withRows("#.......")), 1, PENALTY_N1 + 2},
+	// a row that ends like the next one starts is not a run of 6
+	{"N1 no carry over between rows", withRows("#.#..###", "###.#.#."), 1, 0},
+	{"N1 no carry over between columns", transpose(withRows("#.#..###", "###.#.#.")), 1, 0},
+
+	{"N2 dark block", []string{"###", "###", "###"}, 2, 4 * PENALTY_N2},
+	{"N2 light block", []string{"..#", "..#", "##."}, 2, PENALTY_N2},
+	{"N2 no block", []string{"#.#", ".#.", "#.#"}, 2, 0},
+
+	// the quiet zone gives the 4 light modules of a pattern at the edge
+	{"N3 pattern at the left edge", lineSymbol("#.###.#.#.#"), 3, PENALTY_N3},
+	{"N3 pattern at the right edge", lineSymbol("#.#.#.###.#"), 3, PENALTY_N3},
+	{"N3 pattern at the top edge", transpose(lineSymbol("#.###.#.#.#")), 3, PENALTY_N3},
+	{"N3 pattern at the bottom edge", transpose(lineSymbol("#.#.#.###.#")), 3, PENALTY_N3},
+	{"N3 pattern one module from the edge", lineSymbol(".#.###.##.#"), 3, PENALTY_N3},
+	{"N3 pattern without light modules", lineSymbol("#.#.###.#.#"), 3, 0},
+	{"N3 pattern with light modules on both sides", lineSymbol("....#.###.#...."), 3, PENALTY_N3},
+
+	// the dark modules are counted against every module of the symbol
+	{"N4 half dark", darkFirst(10, 50), 4, 0},
+	{"N4 45% dark", darkFirst(10, 45), 4, PENALTY_N4},
+	{"N4 44% dark", darkFirst(10, 44), 4, PENALTY_N4},
+	{"N4 40% dark", darkFirst(10, 40), 4, 2 * PENALTY_N4},
+	{"N4 all light", darkFirst(10, 0), 4, 10 * PENALTY_N4},
+	{"N4 all dark", darkFirst(10, 100), 4, 10 * PENALTY_N4},
+	{"N4 199 of 441 dark", darkFirst(21, 199), 4, 0},
+	{"N4 198 of 441 dark", darkFirst(21, 198), 4, PENALTY_N4},
+}
+
+func rulePoints(penalty Penalty, rule int) uint {
+	return [4]uint{penalty.N1, penalty.N2, penalty.N3, penalty.N4}[rule-1]
+}
+
+func TestEvaluateRules(t *testing.T) {
+	for _, test := range ruleCases {
+		t.Run(test.name, func(t *testing.T) {
+			if got := rulePoints(Evaluate(symbolFromRows(test.rows)), test.rule); got != test.want {
+				t.Errorf("got N%d %d, want %d", test.rule, got, test.want)
+			}
+		})
+	}
+}
+
+// annexCodewords are the data and error correction codewords of "01234567" in version
+// 1-M, from the encoding example in the annex of ISO/IEC 18004
+var annexCodewords = []byte{
+	16, 32, 12, 86, 97, 128, 236, 17, 236, 17, 236, 17, 236, 17, 236, 17,
+	165, 36, 212, 193, 237, 54, 199, 135, 44, 85,
+}
+
+// annexMasks are the mask conditions of the spec, i is the row and j the column
+var annexMasks = [8]func(i int, j int) bool{
+	func(i int, j int) bool { return (i+j)%2 == 0 },
+	func(i int, j int) bool { return i%2 == 0 },
+	func(i int, j int) bool { return j%3 == 0 },
+	func(i int, j int) bool { return (i+j)%3 == 0 },
+	func(i int, j int) bool { return (i/2+j/3)%2 == 0 },
+	func(i int, j int) bool { return i*j%2+i*j%3 == 0 },
+	func(i int, j int) bool { return (i*j%2+i*j%3)%2 == 0 },
+	func(i int, j int) bool { return ((i+j)%2+i*j%3)%2 == 0 },
+}
+
+// annexSymbol builds the version 1-M symbol of the codewords with the mask, written out
+// from the spec so it does not share code with the generator
+func annexSymbol(codewords []byte, mask int) [][]uint8 {
+	const size = 21
+	dark := make([][]bool, size)
+	function := make([][]bool, size)
+	for i := range size {
+		dark[i] = make([]bool, size)
+		function[i] = make([]bool, size)
+	}
+	set := func(i int, j int, value bool) {
+		dark[i][j] = value
+		function[i][j] = true
+	}
+
+	// finder patterns with their separators, the format areas are written later
+	for _, corner := range [3][2]int{{0, 0}, {0, size - 7}, {size - 7, 0}} {
+		for i := -1; i <= 7; i++ {
+			for j := -1; j <= 7; j++ {
+				if i+corner[0] < 0 || i+corner[0] >= size || j+corner[1] < 0 || j+corner[1] >= size {
+					continue
+				}
+				ring := max(utils.Abs(i-3), utils.Abs(j-3))
+				set(i+corner[0], j+corner[1], ring != 2 && ring != 4)
+			}
+		}
+	}
+	for k := 8; k < size-8; k++ {
+		set(6, k, k%2 == 0)
+		set(k, 6, k%2 == 0)
+	}
+
+	// error level M is 00, then the mask, then the BCH(15,5) remainder, xored with 101010000010010
+	format := mask << 10
+	for bit := 14; bit >= 10; bit-- {
+		if format>>bit&1 == 1 {
+			format ^= 0x537 << (bit - 10)
+		}
+	}
+	format = (mask<<10 | format) ^ 0x5412
+	formatBit := func(bit int) bool {
+		return format>>bit&1 == 1
+	}
+	for bit := range 6 {
+		set(bit, 8, formatBit(bit))
+	}
+	set(7, 8, formatBit(6))
+	set(8, 8, formatBit(7))
+	set(8, 7, formatBit(8))
+	for bit := 9; bit < 15; bit++ {
+		set(8, 14-bit, formatBit(bit))
+	}
+	for bit := range 8 {
+		set(8, size-1-bit, formatBit(bit))
+	}
+	for bit := 8; bit < 15; bit++ {
+		set(size-15+bit, 8, formatBit(bit))
+	}
+	set(size-8, 8, true)
+
+	// codewords go up and down in columns of two modules from the bottom right corner
+	bit := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vertical := range size {
+			i := vertical
+			if upward {
+				i = size - 1 - vertical
+			}
+			for _, j := range [2]int{right, right - 1} {
+				if function[i][j] || bit >= len(codewords)*8 {
+					continue
+				}
+				dark[i][j] = (codewords[bit/8]>>(7-bit%8)&1 == 1) != annexMasks[mask](i, j)
+				bit++
+			}
+		}
+	}
+
+	symbol := make([][]uint8, size)
+	for i := range size {
+		symbol[i] = make([]uint8, size)
+		for j := range size {
+			symbol[i][j] = drawer.WHITE_COLOR
+			if dark[i][j] {
+				symbol[i][j] = drawer.BLACK_COLOR
+			}
+		}
+	}
+	return symbol
+}
+
+func TestAnnexExample(t *testing.T) {
+	bestMask, bestTotal := -1, uint(0)
+	for mask := range annexMasks {
+		total := Evaluate(annexSymbol(annexCodewords, mask)).Total()
+		if bestMask == -1 || total < bestTotal {
+			bestMask, bestTotal = mask, total
+		}
+	}
+	// mask pattern 010
+	if bestMask != 2 {
+		t.Errorf("got mask %d, the annex picks mask 2", bestMask)
+	}
+}
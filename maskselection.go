@@ -0,0 +1,124 @@
+package main
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/generator"
+	"QRCodeGenerator/maskscore"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// MaskSelector chooses the mask of a symbol, QRFinal has the data but no mask yet. The
+// mask must be one of generator.GetMaskPatterns.
+type MaskSelector interface {
+	SelectMask(QRVersionInfo QRCodeInfo, QRTemplate [][]uint8, QRFinal [][]uint8) (MaskPattern, error)
+}
+
+// PenaltyMaskSelector picks the mask with the lowest penalty, the one the spec asks for
+type PenaltyMaskSelector struct{}
+
+func (PenaltyMaskSelector) SelectMask(QRVersionInfo QRCodeInfo, QRTemplate [][]uint8, QRFinal [][]uint8) (MaskPattern, error) {
+	return getBestMaskPattern(QRVersionInfo, QRTemplate, QRFinal), nil
+}
+
+// FixedMaskSelector always uses Mask, to reproduce codes made by other generators
+type FixedMaskSelector struct {
+	Mask MaskPattern
+}
+
+func (selector FixedMaskSelector) SelectMask(QRVersionInfo QRCodeInfo, QRTemplate [][]uint8, QRFinal [][]uint8) (MaskPattern, error) {
+	if !slices.Contains(generator.GetMaskPatterns(), selector.Mask) {
+		return 0, errors.New("unknown mask pattern")
+	}
+	return selector.Mask, nil
+}
+
+// FastMaskSelector only counts the 2x2 blocks and the balance of dark modules of every
+// mask, made for bulk jobs. Codes are still readable but the mask may not be the one
+// PenaltyMaskSelector picks.
+type FastMaskSelector struct{}
+
+func (FastMaskSelector) SelectMask(QRVersionInfo QRCodeInfo, QRTemplate [][]uint8, QRFinal [][]uint8) (MaskPattern, error) {
+	var bestMask MaskPattern
+	var lowestScore uint = math.MaxUint
+	for _, penalty := range getBitsetMaskScores(QRVersionInfo, QRTemplate, QRFinal, maskscore.ScoreBlocks) {
+		if score := penalty.Total(); score < lowestScore {
+			bestMask = penalty.Mask
+			lowestScore = score
+		}
+	}
+	return bestMask, nil
+}
+
+// ObjectiveMaskSelector picks the mask whose symbol has the highest Objective, like the
+// one that looks most like a picture with imageSimilarity
+type ObjectiveMaskSelector struct {
+	Objective func(symbol [][]uint8) float64
+}
+
+func (selector ObjectiveMaskSelector) SelectMask(QRVersionInfo QRCodeInfo, QRTemplate [][]uint8, QRFinal [][]uint8) (MaskPattern, error) {
+	if selector.Objective == nil {
+		return 0, errors.New("mask objective is missing")
+	}
+	var bestMask MaskPattern
+	bestValue := math.Inf(-1)
+	for _, mask := range generator.GetMaskPatterns() {
+		value := selector.Objective(getMaskedSymbol(QRVersionInfo, mask, QRTemplate, QRFinal))
+		if value > bestValue {
+			bestMask = mask
+			bestValue = value
+		}
+	}
+	return bestMask, nil
+}
+
+// imageSimilarity is an objective with the fraction of modules that have the color of the
+// picture stretched over the symbol, a pixel darker than middle gray wants a dark module
+func imageSimilarity(picture image.Image) func(symbol [][]uint8) float64 {
+	fill := drawer.ImageFill{Image: picture}
+	return func(symbol [][]uint8) float64 {
+		size := len(symbol)
+		if size == 0 {
+			return 0
+		}
+		matches := 0
+		for i := range size {
+			for j := range size {
+				gray := color.GrayModel.Convert(fill.ModuleColor(i, j, size)).(color.Gray)
+				if (gray.Y < 128) == (symbol[i][j] == drawer.BLACK_COLOR) {
+					matches++
+				}
+			}
+		}
+		return float64(matches) / float64(size*size)
+	}
+}
+
+// getMaskSelector returns the selector of a strategy: "penalty", "fast" or "picture",
+// which needs the picture the symbol should look like. A mask from 0 to 7 is always
+// used instead of the strategy, -1 leaves the choice to it.
+func getMaskSelector(strategy string, mask int, picture image.Image) (MaskSelector, error) {
+	if mask >= 0 {
+		selector := FixedMaskSelector{Mask: MaskPattern(mask)}
+		if !slices.Contains(generator.GetMaskPatterns(), selector.Mask) {
+			return nil, errors.New("unknown mask pattern: " + strconv.Itoa(mask))
+		}
+		return selector, nil
+	}
+	switch strategy {
+	case "penalty":
+		return PenaltyMaskSelector{}, nil
+	case "fast":
+		return FastMaskSelector{}, nil
+	case "picture":
+		if picture == nil {
+			return nil, errors.New("the picture mask strategy needs a picture")
+		}
+		return ObjectiveMaskSelector{Objective: imageSimilarity(picture)}, nil
+	}
+	return nil, errors.New("unknown mask strategy: " + strategy)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/generator"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"testing"
+)
+
+// getTestSymbol returns the info, template and unmasked symbol the selectors get
+func getTestSymbol(t testing.TB, data string) (QRCodeInfo, [][]uint8, [][]uint8) {
+	t.Helper()
+	info, err := getQRInfoByData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info, generateQRTemplate(info), generateQR(&info, QR_CODE_STEP_REMINDER_BITS)
+}
+
+func sameSymbol(a [][]uint8, b [][]uint8) bool {
+	return slices.EqualFunc(a, b, slices.Equal)
+}
+
+func TestPenaltyMaskSelector(t *testing.T) {
+	info, QRTemplate, QRFinal := getTestSymbol(t, "https://example.com/penalty")
+	mask, err := PenaltyMaskSelector{}.SelectMask(info, QRTemplate, QRFinal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := getBestMaskPattern(info, QRTemplate, QRFinal); mask != want {
+		t.Errorf("got mask %d, want %d", mask, want)
+	}
+}
+
+func TestFixedMaskSelector(t *testing.T) {
+	info, QRTemplate, QRFinal := getTestSymbol(t, "https://example.com/fixed")
+	for _, mask := range generator.GetMaskPatterns() {
+		generatedInfo := info
+		QRArray, err := generateQRWithMaskSelector(&generatedInfo, QR_CODE_STEP_MASK, FixedMaskSelector{Mask: mask})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if generatedInfo.MaskPatern != mask {
+			t.Errorf("mask %d: the info has mask %d", mask, generatedInfo.MaskPatern)
+		}
+		if !sameSymbol(QRArray, getMaskedSymbol(info, mask, QRTemplate, QRFinal)) {
+			t.Errorf("mask %d: the symbol does not have the mask", mask)
+		}
+	}
+
+	for _, mask := range []MaskPattern{8, 9, 255} {
+		if _, err := generateQRWithMaskSelector(&info, QR_CODE_STEP_MASK, FixedMaskSelector{Mask: mask}); err == nil {
+			t.Errorf("mask %d did not fail", mask)
+		}
+	}
+}
+
+func TestObjectiveMaskSelector(t *testing.T) {
+	info, QRTemplate, QRFinal := getTestSymbol(t, "https://example.com/objective")
+	darkModules := func(symbol [][]uint8) float64 {
+		count := 0
+		for i := range symbol {
+			for j := range symbol[i] {
+				if symbol[i][j] == drawer.BLACK_COLOR {
+					count++
+				}
+			}
+		}
+		return float64(count)
+	}
+
+	var want MaskPattern
+	mostDark := -1.0
+	for _, mask := range generator.GetMaskPatterns() {
+		if dark := darkModules(getMaskedSymbol(info, mask, QRTemplate, QRFinal)); dark > mostDark {
+			want, mostDark = mask, dark
+		}
+	}
+	mask, err := ObjectiveMaskSelector{Objective: darkModules}.SelectMask(info, QRTemplate, QRFinal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mask != want {
+		t.Errorf("got mask %d, want %d with the most dark modules", mask, want)
+	}
+
+	if _, err := generateQRWithMaskSelector(&info, QR_CODE_STEP_MASK, ObjectiveMaskSelector{}); err == nil {
+		t.Error("a selector without objective did not fail")
+	}
+}
+
+// symbolPicture draws the symbol with a pixel per module
+func symbolPicture(symbol [][]uint8) image.Image {
+	picture := image.NewGray(image.Rect(0, 0, len(symbol), len(symbol)))
+	for i := range symbol {
+		for j := range symbol[i] {
+			if symbol[i][j] != drawer.BLACK_COLOR {
+				picture.SetGray(j, i, color.Gray{255})
+			}
+		}
+	}
+	return picture
+}
+
+func TestPictureMaskSelector(t *testing.T) {
+	info, QRTemplate, QRFinal := getTestSymbol(t, "https://example.com/picture")
+	for _, want := range generator.GetMaskPatterns() {
+		symbol := getMaskedSymbol(info, want, QRTemplate, QRFinal)
+		if similarity := imageSimilarity(symbolPicture(symbol))(symbol); similarity != 1 {
+			t.Errorf("mask %d: the symbol has a similarity of %g with its own picture", want, similarity)
+		}
+		selector, err := getMaskSelector("picture", -1, symbolPicture(symbol))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mask, err := selector.SelectMask(info, QRTemplate, QRFinal); err != nil || mask != want {
+			t.Errorf("got mask %d and error %v, want mask %d that looks like the picture", mask, err, want)
+		}
+	}
+}
+
+func TestGetMaskSelector(t *testing.T) {
+	picture := image.NewGray(image.Rect(0, 0, 4, 4))
+	tests := []struct {
+		strategy string
+		mask     int
+		picture  image.Image
+		want     MaskSelector
+	}{
+		{"penalty", -1, nil, PenaltyMaskSelector{}},
+		{"fast", -1, nil, FastMaskSelector{}},
+		{"fast", 5, nil, FixedMaskSelector{Mask: 5}},
+		{"picture", -1, picture, ObjectiveMaskSelector{}},
+		{"picture", -1, nil, nil},
+		{"penalty", 8, nil, nil},
+		{"best", -1, nil, nil},
+	}
+	for _, test := range tests {
+		selector, err := getMaskSelector(test.strategy, test.mask, test.picture)
+		if (err != nil) != (test.want == nil) {
+			t.Errorf("%s with mask %d: got error %v", test.strategy, test.mask, err)
+			continue
+		}
+		if _, isObjective := selector.(ObjectiveMaskSelector); isObjective {
+			_, wantObjective := test.want.(ObjectiveMaskSelector)
+			if !wantObjective {
+				t.Errorf("%s with mask %d: got an objective selector", test.strategy, test.mask)
+			}
+			continue
+		}
+		if selector != test.want {
+			t.Errorf("%s with mask %d: got %#v, want %#v", test.strategy, test.mask, selector, test.want)
+		}
+	}
+}
+
+func TestFastMaskSelector(t *testing.T) {
+	for _, data := range []string{"01234567", "HELLO WORLD", "https://example.com/fast", maskSelectorBenchmarkData} {
+		info, QRTemplate, QRFinal := getTestSymbol(t, data)
+		penalties, _ := getMaskPenalties(info, QRTemplate, QRFinal)
+		var want MaskPattern
+		var lowestScore uint = math.MaxUint
+		for _, penalty := range penalties {
+			if score := penalty.N2 + penalty.N4; score < lowestScore {
+				want, lowestScore = penalty.Mask, score
+			}
+		}
+		mask, err := FastMaskSelector{}.SelectMask(info, QRTemplate, QRFinal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mask != want {
+			t.Errorf("%q: got mask %d, want %d with the fewest blocks and best balance", data, mask, want)
+		}
+	}
+}
+
+// maskSelectorBenchmarkData is a label URL, it needs a version 5 symbol
+const maskSelectorBenchmarkData = "https://example.com/batch/labels?customer=0042&order=2024-0001&item=17&lot=A7F3&serial=000000123456789"
+
+func benchmarkMaskSelector(b *testing.B, maskSelector MaskSelector) {
+	info, QRTemplate, QRFinal := getTestSymbol(b, maskSelectorBenchmarkData)
+	b.ResetTimer()
+	for range b.N {
+		if _, err := maskSelector.SelectMask(info, QRTemplate, QRFinal); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPenaltyMaskSelector(b *testing.B) {
+	benchmarkMaskSelector(b, PenaltyMaskSelector{})
+}
+
+func BenchmarkFastMaskSelector(b *testing.B) {
+	benchmarkMaskSelector(b, FastMaskSelector{})
+}
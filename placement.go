@@ -0,0 +1,98 @@
+package main
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/utils"
+	"io"
+)
+
+type codewordLocation struct {
+	Block           int
+	Index           int // index inside the block, error correction codewords come after the data ones
+	ErrorCorrection bool
+}
+
+// getInterleavedCodewords returns where each codeword of the final message comes
+// from, following the order used by getStructuredFinalMessage
+func getInterleavedCodewords(codeWords ERCodeWords) []codewordLocation {
+	blocks := codeWords.BlocksGroup1 + codeWords.BlocksGroup2
+	dataPerBlock := func(block int) int {
+		if block < codeWords.BlocksGroup1 {
+			return codeWords.DataCodeWordsPerGroup1
+		}
+		return codeWords.DataCodeWordsPerGroup2
+	}
+
+	locations := make([]codewordLocation, 0, codeWords.Total+blocks*codeWords.ECCWPerBlock)
+	for i := range utils.GetMax(codeWords.DataCodeWordsPerGroup1, codeWords.DataCodeWordsPerGroup2) {
+		for block := range blocks {
+			if i < dataPerBlock(block) {
+				locations = append(locations, codewordLocation{Block: block, Index: i})
+			}
+		}
+	}
+	for i := range codeWords.ECCWPerBlock {
+		for block := range blocks {
+			locations = append(locations, codewordLocation{Block: block, Index: dataPerBlock(block) + i, ErrorCorrection: true})
+		}
+	}
+	return locations
+}
+
+// getCodewordIndexes returns for every module the index of its codeword in the final
+// message, -1 for function patterns and remainder bits
+func getCodewordIndexes(QRVersionInfo QRCodeInfo) [][]int {
+	codewordIndexes := make([][]int, QRVersionInfo.Size)
+	for i := range codewordIndexes {
+		codewordIndexes[i] = make([]int, QRVersionInfo.Size)
+		for j := range codewordIndexes[i] {
+			codewordIndexes[i][j] = -1
+		}
+	}
+	for _, placement := range getPlacementMap(QRVersionInfo) {
+		codewordIndexes[placement.Row][placement.Col] = placement.Index
+	}
+	return codewordIndexes
+}
+
+// getPlacementMap returns the block, codeword and bit written on every data module,
+// following the walk of addDataToQRCode and the interleaving of getStructuredFinalMessage
+func getPlacementMap(QRVersionInfo QRCodeInfo) []drawer.ModulePlacement {
+	codewords := getInterleavedCodewords(QRVersionInfo.CodeWords)
+	positions := getDataModulePositions(generateQRTemplate(QRVersionInfo))
+	placements := make([]drawer.ModulePlacement, len(positions))
+	for index, position := range positions {
+		placement := drawer.ModulePlacement{Row: position[0], Col: position[1]}
+		if codewordIndex := index / 8; codewordIndex < len(codewords) {
+			placement.Kind = drawer.PlacementKind_Data
+			if codewords[codewordIndex].ErrorCorrection {
+				placement.Kind = drawer.PlacementKind_ErrorCorrection
+			}
+			placement.Block = codewords[codewordIndex].Block
+			placement.Codeword = codewords[codewordIndex].Index
+			placement.Index = codewordIndex
+			placement.Bit = 7 - index%8
+		} else {
+			placement.Kind = drawer.PlacementKind_Remainder
+			placement.Block, placement.Codeword, placement.Index = -1, -1, -1
+			placement.Bit = index - len(codewords)*8
+		}
+		placements[index] = placement
+	}
+	return placements
+}
+
+// renderPlacementMap writes the placement map of the code of stringToEncode, as an SVG
+// drawn over the symbol or as JSON with the version, error level and mask
+func renderPlacementMap(w io.Writer, stringToEncode string, svg bool) error {
+	QRversion, err := getQRInfoByData(stringToEncode)
+	if err != nil {
+		return err
+	}
+	QRArray := generateQR(&QRversion, QR_CODE_STEP_MASK)
+	placements := getPlacementMap(QRversion)
+	if svg {
+		return drawer.WritePlacementSVG(w, QRArray, placements, 0)
+	}
+	return drawer.WritePlacementJSON(w, placements, QRversion)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"QRCodeGenerator/drawer"
+	"QRCodeGenerator/generator"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestPlacementMapCoversDataModules(t *testing.T) {
+	for _, info := range getTestQRInfo(t) {
+		placements := getPlacementMap(info)
+		_, roles := drawer.GetQRTemplate(info)
+		seen := make(map[[2]int]bool)
+		counts := make(map[drawer.PlacementKind]int)
+		for _, placement := range placements {
+			position := [2]int{placement.Row, placement.Col}
+			if seen[position] {
+				t.Fatalf("version %d-%s: module %v is placed twice", info.Version, info.ErrorLevel.Letter(), position)
+			}
+			if roles[placement.Row][placement.Col] != drawer.ModuleRole_Data {
+				t.Fatalf("version %d-%s: module %v is a function pattern", info.Version, info.ErrorLevel.Letter(), position)
+			}
+			seen[position] = true
+			counts[placement.Kind]++
+		}
+
+		dataModules := 0
+		for i := range roles {
+			for j := range roles[i] {
+				if roles[i][j] == drawer.ModuleRole_Data {
+					dataModules++
+				}
+			}
+		}
+		blocks := info.CodeWords.BlocksGroup1 + info.CodeWords.BlocksGroup2
+		want := map[drawer.PlacementKind]int{
+			drawer.PlacementKind_Data:            info.CodeWords.Total * 8,
+			drawer.PlacementKind_ErrorCorrection: blocks * info.CodeWords.ECCWPerBlock * 8,
+			drawer.PlacementKind_Remainder:       generator.ReminderBits[info.Version],
+		}
+		for kind, count := range want {
+			if counts[kind] != count {
+				t.Errorf("version %d-%s: %d %s modules, want %d", info.Version, info.ErrorLevel.Letter(), counts[kind], kind, count)
+			}
+		}
+		if len(placements) != dataModules {
+			t.Errorf("version %d-%s: %d placements for %d data modules", info.Version, info.ErrorLevel.Letter(), len(placements), dataModules)
+		}
+	}
+}
+
+// TestPlacementMapMatchesSymbol checks every placement against the codewords of its block
+// before they are interleaved, so the map and the writing of the symbol agree
+func TestPlacementMapMatchesSymbol(t *testing.T) {
+	for _, data := range []string{"01234567", "HELLO WORLD", "https://example.com/placement", "a longer text that needs a code with blocks in both groups"} {
+		for _, errorLevel := range generator.GetErrorLevels() {
+			var info QRCodeInfo
+			found := false
+			for version := 1; version <= generator.MAX_SUPPORTED_VERSION && !found; version++ {
+				info, found = getQRInfoByVersion(data, version, errorLevel)
+			}
+			if !found {
+				continue
+			}
+			QRArray := generateQR(&info, QR_CODE_STEP_REMINDER_BITS)
+
+			dataBits := getString_Encoded(info)
+			dataBits = append(getCharacterCount_Binary(info), dataBits...)
+			dataBits = append(getEncodeMode_Binary(info), dataBits...)
+			dataBits = append(dataBits, getPadingBits_Binary(info, len(dataBits))...)
+			errorCorrection := getCodeWords_Encoded(info, dataBits)
+
+			blockStart := 0
+			blockBits := make([][]bool, len(errorCorrection))
+			for block := range blockBits {
+				length := info.CodeWords.DataCodeWordsPerGroup1 * 8
+				if block >= info.CodeWords.BlocksGroup1 {
+					length = info.CodeWords.DataCodeWordsPerGroup2 * 8
+				}
+				blockBits[block] = append(append([]bool{}, dataBits[blockStart:blockStart+length]...), errorCorrection[block]...)
+				blockStart += length
+			}
+
+			for _, placement := range getPlacementMap(info) {
+				if placement.Kind == drawer.PlacementKind_Remainder {
+					continue
+				}
+				want := blockBits[placement.Block][placement.Codeword*8+7-placement.Bit]
+				if got := QRArray[placement.Row][placement.Col] == drawer.BLACK_COLOR; got != want {
+					t.Fatalf("%q %d-%s: %s has %v, want %v", data, info.Version, info.ErrorLevel.Letter(), placement, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestWritePlacementJSON(t *testing.T) {
+	info, err := getQRInfoByData("HELLO WORLD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	placements := getPlacementMap(info)
+	var buffer bytes.Buffer
+	if err := drawer.WritePlacementJSON(&buffer, placements, info); err != nil {
+		t.Fatal(err)
+	}
+	var export drawer.PlacementExport
+	if err := json.Unmarshal(buffer.Bytes(), &export); err != nil {
+		t.Fatal(err)
+	}
+	if export.Version != info.Version || export.Size != info.Size || len(export.Modules) != len(placements) {
+		t.Fatalf("got version %d, size %d and %d modules", export.Version, export.Size, len(export.Modules))
+	}
+	for index := range placements {
+		if export.Modules[index] != placements[index] {
+			t.Fatalf("module %d is %s, want %s", index, export.Modules[index], placements[index])
+		}
+	}
+
+	buffer.Reset()
+	if err := drawer.WritePlacementSVG(&buffer, generateQR(&info, QR_CODE_STEP_MASK), placements, 0); err != nil {
+		t.Fatal(err)
+	}
+	if titles := bytes.Count(buffer.Bytes(), []byte("<title>")); titles != len(placements) {
+		t.Fatalf("the SVG has %d placements, want %d", titles, len(placements))
+	}
+}
+
+func TestRenderPlacementMap(t *testing.T) {
+	const data = "HELLO WORLD"
+	info, err := getQRInfoByData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generateQR(&info, QR_CODE_STEP_MASK)
+
+	var buffer bytes.Buffer
+	if err := renderPlacementMap(&buffer, data, false); err != nil {
+		t.Fatal(err)
+	}
+	var export drawer.PlacementExport
+	if err := json.Unmarshal(buffer.Bytes(), &export); err != nil {
+		t.Fatal(err)
+	}
+	if export.Version != info.Version || export.MaskPattern != int(info.MaskPatern) || len(export.Modules) != len(getPlacementMap(info)) {
+		t.Errorf("got version %d, mask %d and %d modules", export.Version, export.MaskPattern, len(export.Modules))
+	}
+
+	buffer.Reset()
+	if err := renderPlacementMap(&buffer, data, true); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buffer.Bytes(), []byte("<svg")) {
+		t.Errorf("unexpected SVG: %.100s", buffer.String())
+	}
+}
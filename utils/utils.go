@@ -7,6 +7,13 @@ func GetMax(x int, y int) int {
 	return y
 }
 
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
 func Pow16(x, y uint16) uint16 {
 	if y == 0 {
 		return 1